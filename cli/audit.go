@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/mdy/melody/internal/osv"
+	"github.com/mdy/melody/project"
+	"github.com/mdy/melody/provider"
+	"github.com/mdy/melody/resolver"
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/version"
+	"github.com/urfave/cli"
+	"os"
+)
+
+// Vulnerability affecting a locked package
+type auditFinding struct {
+	Name          string `json:"name"`
+	Version       string `json:"version"`
+	ID            string `json:"id"`
+	Summary       string `json:"summary,omitempty"`
+	Severity      string `json:"severity"`
+	Fixed         string `json:"fixed"`
+	Constraint    string `json:"constraint,omitempty"`
+	CompatibleFix bool   `json:"compatibleFix"`
+
+	level osv.Severity
+}
+
+func audit(c *cli.Context) error {
+	if len(c.Args()) != 0 {
+		return fmt.Errorf("`audit` command takes no arguments. See '%s audit --help'.", c.App.Name)
+	}
+
+	dbPath := c.String("db")
+	if dbPath == "" {
+		return fmt.Errorf("`audit` needs a vulnerability database (--db). See '%s audit --help'.", c.App.Name)
+	}
+
+	failOn, err := osv.ParseSeverity(c.String("fail-on"))
+	if err != nil {
+		return err
+	}
+
+	db, err := osv.Load(dbPath)
+	if err != nil {
+		return err
+	}
+
+	wDir, _ := os.Getwd()
+	proj, err := project.Load(wDir)
+	if err != nil {
+		return err
+	}
+
+	findings, err := auditProject(proj, db)
+	if err != nil {
+		return err
+	}
+
+	if isJSONFormat(c) {
+		output := struct {
+			Vulnerabilities []*auditFinding `json:"vulnerabilities"`
+		}{findings}
+		if err := printJSON(output); err != nil {
+			return err
+		}
+	} else {
+		printFindings(findings)
+	}
+
+	failed := 0
+	for _, f := range findings {
+		if f.level >= failOn {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		msg := fmt.Sprintf("♫ %d vulnerabilities with %s severity or above", failed, failOn)
+		return cli.NewExitError(msg, 1)
+	}
+	return nil
+}
+
+// Match every locked package against the database
+func auditProject(proj *project.Project, db *osv.Database) ([]*auditFinding, error) {
+	source, parser := proj.Provider(), flex.VersionParser
+	findings := []*auditFinding{}
+
+	for _, spec := range proj.Locked.Specifications() {
+		if _, ok := spec.(provider.ReleaseSpec); ok {
+			continue
+		}
+
+		v, err := parser.Parse(spec.Version())
+		if err != nil {
+			continue // Nothing to match without a version
+		}
+
+		matches, err := db.Matches(spec.Name(), v, parser)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only direct dependencies are constrained by Melody.toml
+		constraint := version.Set{version.Interval{}}
+		r, direct := proj.Config.Dependencies[spec.Name()]
+		if req, ok := source.NewRequirement(spec.Name(), r).(resolver.RangedRequirement); direct && ok {
+			if set, err := req.VersionSet(); err == nil {
+				constraint = set
+			}
+		}
+
+		// Fixes are unaffected versions newer than the locked one
+		newer := version.Set{{Lower: version.Bound{Version: v}}}
+		for _, m := range matches {
+			fixed := m.Affected.Complement().Intersect(newer)
+			findings = append(findings, &auditFinding{
+				Name:          spec.Name(),
+				Version:       spec.Version(),
+				ID:            m.Vulnerability.ID,
+				Summary:       m.Vulnerability.Summary,
+				Severity:      m.Vulnerability.Level().String(),
+				Fixed:         fixed.String(),
+				Constraint:    r,
+				CompatibleFix: !fixed.Intersect(constraint).IsEmpty(),
+				level:         m.Vulnerability.Level(),
+			})
+		}
+	}
+	return findings, nil
+}
+
+func printFindings(findings []*auditFinding) {
+	if len(findings) == 0 {
+		fmt.Println("♫ No known vulnerabilities!")
+		return
+	}
+
+	fmt.Println("♫ Vulnerable packages in the project:")
+	for _, f := range findings {
+		fmt.Printf("  * %s %s: %s (%s) %s\n", f.Name, f.Version, f.ID, f.Severity, f.Summary)
+		fmt.Printf("      fixed: %s\n", f.Fixed)
+
+		compatible := "no"
+		if f.CompatibleFix {
+			compatible = "yes"
+		}
+		if f.Constraint != "" {
+			fmt.Printf("      fix within Melody.toml \"%s\": %s\n", f.Constraint, compatible)
+		} else {
+			fmt.Printf("      fix available: %s (not in Melody.toml)\n", compatible)
+		}
+	}
+}
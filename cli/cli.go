@@ -28,6 +28,14 @@ func Main(version string) {
 			Value: "fatal",
 			Usage: "log level",
 		},
+		cli.BoolFlag{
+			Name:  "quiet, q",
+			Usage: "no progress output",
+		},
+		cli.BoolFlag{
+			Name:  "verbose",
+			Usage: "show resolver debug output",
+		},
 	}
 
 	// Configure based on global CLI flags
@@ -66,16 +74,53 @@ var (
 			ShortName: "i",
 			Usage:     "Install dependencies",
 			Action:    install,
+			Flags:     []cli.Flag{formatFlag, resolutionFlag, recordFlag, eventsFlag},
 		}, {
 			Name:      "update",
 			ShortName: "u",
 			Usage:     "Update dependencies",
 			Action:    update,
+			Flags: []cli.Flag{formatFlag, resolutionFlag, recordFlag, eventsFlag,
+				cli.BoolFlag{
+					Name:  "conservative",
+					Usage: "Change as few locked packages as possible",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Show changes to Melody.lock without writing it or ./vendor",
+				},
+			},
 		}, {
 			Name:      "outdated",
 			ShortName: "o",
 			Usage:     "Show outdated dependencies",
 			Action:    outdated,
+			Flags: []cli.Flag{templateFormatFlag, resolutionFlag,
+				cli.BoolFlag{
+					Name:  "exit-code",
+					Usage: "exit with status 1 if anything is outdated (for CI)",
+				},
+				cli.BoolFlag{
+					Name:  "locked",
+					Usage: "skip resolving requirements (compatible versions are unknown)",
+				},
+			},
+		}, {
+			Name:   "audit",
+			Usage:  "Check locked packages for known vulnerabilities",
+			Action: audit,
+			Flags: []cli.Flag{formatFlag,
+				cli.StringFlag{
+					Name:   "db",
+					Usage:  "OSV vulnerability database `path` (file or directory)",
+					EnvVar: "MELODY_AUDIT_DB",
+				},
+				cli.StringFlag{
+					Name:  "fail-on",
+					Value: "low",
+					Usage: "exit non-zero at this `severity` or above (low, moderate, high or critical)",
+				},
+			},
 		}, {
 			Name:   "add",
 			Usage:  "Add package to dependencies",
@@ -88,10 +133,16 @@ var (
 			Name:   "lint",
 			Usage:  "Validate configuration",
 			Action: lint,
+		}, {
+			Name:   "licenses",
+			Usage:  "Show licenses of installed releases",
+			Action: licenses,
+			Flags:  []cli.Flag{formatFlag},
 		}, {
 			Name:   "list",
 			Usage:  "List all dependencies",
 			Action: list,
+			Flags:  []cli.Flag{templateFormatFlag},
 		}, {
 			Name:   "get",
 			Usage:  "Download and install a package",
@@ -106,10 +157,78 @@ var (
 					Usage: "Force update",
 				},
 			},
+		}, {
+			Name:   "resolve",
+			Usage:  "Resolve dependencies without installing",
+			Action: resolve,
+			Flags: []cli.Flag{formatFlag, resolutionFlag, recordFlag, eventsFlag,
+				cli.StringFlag{
+					Name:  "replay",
+					Usage: "replay a recorded session from `file`",
+				},
+			},
+		}, {
+			Name:   "tree",
+			Usage:  "Show dependencies as a tree",
+			Action: tree,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "invert",
+					Usage: "show the packages that depend on a `package`",
+				},
+			},
+		}, {
+			Name:   "graph",
+			Usage:  "Export the dependency graph",
+			Action: exportGraph,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format",
+					Value: "dot",
+					Usage: "output format (dot, json or mermaid)",
+				},
+				cli.StringFlag{
+					Name:  "match",
+					Usage: "only show packages matching a `glob`",
+				},
+				cli.IntFlag{
+					Name:  "depth",
+					Usage: "only show packages up to a `depth` (Melody.toml dependencies are 1)",
+				},
+				cli.BoolFlag{
+					Name:  "releases",
+					Usage: "collapse packages into their releases",
+				},
+				cli.BoolFlag{
+					Name:  "outdated",
+					Usage: "highlight outdated packages",
+				},
+				cli.BoolFlag{
+					Name:  "locked",
+					Usage: "only read Melody.lock (offline, without requirement ranges)",
+				},
+			},
+		}, {
+			Name:   "why",
+			Usage:  "Explain why a package is a dependency",
+			Action: why,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "locked",
+					Usage: "only read Melody.lock (offline, without requirement ranges)",
+				},
+			},
+		}, {
+			Name:      "diff",
+			Usage:     "Compare two lockfiles or git revisions of Melody.lock",
+			ArgsUsage: "[old] [new]",
+			Action:    diff,
+			Flags:     []cli.Flag{formatFlag},
 		}, {
 			Name:   "info",
 			Usage:  "Show project info",
 			Action: info,
+			Flags:  []cli.Flag{templateFormatFlag},
 		},
 	}
 )
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/mdy/melody/project"
+	"github.com/mdy/melody/resolver"
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/urfave/cli"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func diff(c *cli.Context) error {
+	if len(c.Args()) > 2 {
+		return fmt.Errorf("`diff` command takes up to two lockfiles. See '%s diff --help'.", c.App.Name)
+	}
+
+	// Last commit against the working tree by default
+	from, to := "HEAD", "Melody.lock"
+	if len(c.Args()) > 0 {
+		from = c.Args().Get(0)
+	}
+	if len(c.Args()) > 1 {
+		to = c.Args().Get(1)
+	}
+
+	oldGraph, err := readLockfile(from)
+	if err != nil {
+		return err
+	}
+	newGraph, err := readLockfile(to)
+	if err != nil {
+		return err
+	}
+
+	changes := oldGraph.Diff(newGraph, flex.VersionParser)
+	if isJSONFormat(c) {
+		return printJSON(changes)
+	}
+
+	if changes.IsEmpty() {
+		fmt.Printf("♫ No changes between %s and %s\n", from, to)
+		return nil
+	}
+
+	fmt.Printf("♫ Changes from %s to %s:\n", from, to)
+	printGraphDiff(changes)
+	return nil
+}
+
+// Melody.lock from a path (or directory), a git ref, or a ref:path
+func readLockfile(source string) (*resolver.Graph, error) {
+	path := source
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "Melody.lock")
+	}
+
+	if raw, err := ioutil.ReadFile(path); err == nil {
+		return project.DecodeLockfile(raw)
+	}
+
+	// Paths in refs are relative to the current directory, not the repository
+	object := source
+	if !strings.Contains(source, ":") {
+		object = source + ":./Melody.lock"
+	}
+
+	raw, err := exec.Command("git", "show", object).Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok && len(exit.Stderr) > 0 {
+			return nil, fmt.Errorf("%s is not a lockfile or git ref: %s", source, strings.TrimSpace(string(exit.Stderr)))
+		}
+		return nil, fmt.Errorf("%s is not a lockfile or git ref: %s", source, err)
+	}
+	return project.DecodeLockfile(raw)
+}
+
+// Changed packages by kind, then changed dependencies between them
+func printGraphDiff(diff *resolver.GraphDiff) {
+	printChanges("Added", diff.Added, func(s *resolver.ChangedSpec) string {
+		return s.Name + " " + s.NewVersion + changedRevision("", s.NewRevision)
+	})
+	printChanges("Removed", diff.Removed, func(s *resolver.ChangedSpec) string {
+		return s.Name + " " + s.OldVersion + changedRevision("", s.OldRevision)
+	})
+	printChanges("Upgraded", diff.Upgraded, func(s *resolver.ChangedSpec) string {
+		return s.Name + " " + s.OldVersion + " => " + s.NewVersion + changedRevision(s.OldRevision, s.NewRevision)
+	})
+	printChanges("Downgraded", diff.Downgraded, func(s *resolver.ChangedSpec) string {
+		return s.Name + " " + s.OldVersion + " => " + s.NewVersion + changedRevision(s.OldRevision, s.NewRevision)
+	})
+	printChanges("Changed revisions", diff.Revised, func(s *resolver.ChangedSpec) string {
+		return s.Name + " " + s.NewVersion + changedRevision(s.OldRevision, s.NewRevision)
+	})
+
+	printEdges("Added dependencies", diff.AddedEdges)
+	printEdges("Removed dependencies", diff.RemovedEdges)
+}
+
+func printChanges(kind string, changes []*resolver.ChangedSpec, describe func(*resolver.ChangedSpec) string) {
+	if len(changes) == 0 {
+		return
+	}
+
+	fmt.Printf("  %s:\n", kind)
+	for _, s := range changes {
+		fmt.Printf("    * %s\n", describe(s))
+	}
+}
+
+func printEdges(kind string, edges []*resolver.DependencyEdge) {
+	if len(edges) == 0 {
+		return
+	}
+
+	fmt.Printf("  %s:\n", kind)
+	for _, e := range edges {
+		fmt.Printf("    * %s -> %s\n", e.From, e.To)
+	}
+}
+
+// Abbreviated revisions (#1a2b3c4 => #5d6e7f8), if known
+func changedRevision(oldRev, newRev string) string {
+	switch {
+	case newRev == "":
+		return ""
+	case oldRev == "" || oldRev == newRev:
+		return " #" + shortRevision(newRev)
+	}
+	return " (#" + shortRevision(oldRev) + " => #" + shortRevision(newRev) + ")"
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdy/melody/project"
+	"github.com/mdy/melody/resolver"
+	"github.com/urfave/cli"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// Output format flag for commands with machine-readable output
+var formatFlag = cli.StringFlag{
+	Name:  "format",
+	Value: "text",
+	Usage: "output format (text or json)",
+}
+
+// Output format flag for commands that can also print through a Go template
+var templateFormatFlag = cli.StringFlag{
+	Name:  "format",
+	Value: "text",
+	Usage: "output format (text, json or template=`TEMPLATE`)",
+}
+
+// Resolution strategy flag to override Melody.toml
+var resolutionFlag = cli.StringFlag{
+	Name:  "resolution",
+	Usage: "resolution strategy (newest or minimal)",
+}
+
+// Record resolution session for offline replay
+var recordFlag = cli.StringFlag{
+	Name:  "record",
+	Usage: "record resolution session to a JSON `file`",
+}
+
+// Resolution events for other tools
+var eventsFlag = cli.StringFlag{
+	Name:  "events",
+	Usage: "write resolution events as JSON lines to `file`",
+}
+
+// Create the --events file, or return nil without the flag.  Closing
+// a nil file is harmless, so it can always be deferred
+func openEvents(c *cli.Context) (resolver.Events, *os.File, error) {
+	path := c.String("events")
+	if path == "" {
+		return nil, nil, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolver.NewJSONEvents(file), file, nil
+}
+
+// Apply command line overrides to project settings
+func configureProject(c *cli.Context, p *project.Project) {
+	if r := c.String("resolution"); r != "" {
+		p.Config.Resolution = r
+	}
+	p.RecordPath = c.String("record")
+	p.DryRun = c.Bool("dry-run")
+	p.UI = newUI(c)
+}
+
+func isJSONFormat(c *cli.Context) bool {
+	return c.String("format") == "json"
+}
+
+func isTemplateFormat(c *cli.Context) bool {
+	return strings.HasPrefix(c.String("format"), "template=")
+}
+
+// Output meant for scripts rather than people
+func isStructuredFormat(c *cli.Context) bool {
+	return isJSONFormat(c) || isTemplateFormat(c)
+}
+
+// Template given with --format template=...
+func formatTemplate(c *cli.Context) (*template.Template, error) {
+	return parseTemplate(strings.TrimPrefix(c.String("format"), "template="))
+}
+
+// Output template where lists can be joined with {{join .Dependents ","}}
+func parseTemplate(text string) (*template.Template, error) {
+	funcs := template.FuncMap{"join": strings.Join}
+	return template.New("format").Funcs(funcs).Parse(text)
+}
+
+// Execute a template, one line per call (like `go list -f`)
+func printTemplate(w io.Writer, t *template.Template, v interface{}) error {
+	if err := t.Execute(w, v); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// Resolver UI based on global flags.  Machine-readable output
+// keeps STDOUT clean by writing progress to STDERR instead
+func newUI(c *cli.Context) resolver.UI {
+	verbosity := resolver.NormalVerbosity
+	if c.GlobalBool("quiet") {
+		verbosity = resolver.QuietVerbosity
+	} else if c.GlobalBool("verbose") {
+		verbosity = resolver.VerboseVerbosity
+	}
+
+	if isStructuredFormat(c) {
+		return resolver.NewTerminalUI(os.Stderr, verbosity)
+	}
+	return resolver.NewTerminalUI(os.Stdout, verbosity)
+}
+
+// Print resolution errors in the requested format
+func formatError(c *cli.Context, err error) error {
+	vErr, ok := err.(*resolver.VersionConflictError)
+	if !ok || !isJSONFormat(c) {
+		return err
+	}
+
+	output := struct {
+		Conflicts []*resolver.ConflictReport `json:"conflicts"`
+	}{vErr.Report()}
+
+	if err := printJSON(output); err != nil {
+		return err
+	}
+	return cli.NewExitError("", 1)
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/gobwas/glob"
+	"github.com/mdy/melody/project"
+	"github.com/mdy/melody/provider"
+	"github.com/mdy/melody/resolver"
+	"github.com/urfave/cli"
+	"os"
+	"strings"
+)
+
+func exportGraph(c *cli.Context) error {
+	if len(c.Args()) != 0 {
+		return fmt.Errorf("`graph` command takes no arguments. See '%s graph --help'.", c.App.Name)
+	}
+
+	format := c.String("format")
+	if format != "dot" && format != "json" && format != "mermaid" {
+		return fmt.Errorf("Unknown graph format %q (dot, json or mermaid)", format)
+	}
+
+	wDir, _ := os.Getwd()
+	proj, err := project.Load(wDir)
+	if err != nil {
+		return err
+	}
+	configureProject(c, proj)
+	proj.UI = resolver.NewTerminalUI(os.Stderr, resolver.QuietVerbosity)
+
+	opts := &resolver.ExportOptions{Depth: c.Int("depth"), Group: packageNode}
+	if c.Bool("releases") {
+		opts.Group = releaseNode
+	}
+
+	if m := c.String("match"); m != "" {
+		g, err := glob.Compile("{" + m + "}")
+		if err != nil {
+			return err
+		}
+		opts.Match = g.Match
+	}
+
+	if c.Bool("outdated") {
+		outdated, err := findOutdated(proj.Provider(), proj.Locked, proj.Locked, os.Stderr)
+		if err != nil {
+			return err
+		}
+		opts.Newest = func(v *resolver.Vertex) string {
+			if ver, ok := v.Payload.(provider.VersionSpec); ok && ver.ReleaseSpec() != nil {
+				return outdated[ver.ReleaseSpec().Name()].NewVersion
+			}
+			return ""
+		}
+	}
+
+	exported := lockedGraph(c, proj).Export(opts)
+	switch format {
+	case "json":
+		return printJSON(exported)
+	case "mermaid":
+		return exported.WriteMermaid(os.Stdout)
+	}
+	return exported.WriteDOT(os.Stdout)
+}
+
+// Packages are nodes of their own, releases are hidden
+func packageNode(v *resolver.Vertex) string {
+	if _, ok := v.Payload.(provider.ReleaseSpec); ok || strings.HasPrefix(v.Name, "repo://") {
+		return ""
+	}
+	return v.Name
+}
+
+// Packages are collapsed into the node of their release
+func releaseNode(v *resolver.Vertex) string {
+	if ver, ok := v.Payload.(provider.VersionSpec); ok && ver.ReleaseSpec() != nil {
+		return ver.ReleaseSpec().ExternalName()
+	}
+	return packageNode(v)
+}
@@ -9,6 +9,12 @@ import (
 	"text/template"
 )
 
+// Melody.toml with the locked packages of the project
+type projectInfo struct {
+	project.Config
+	Packages []*lockedSpec `json:"packages"`
+}
+
 func info(c *cli.Context) error {
 	infoFmt := "{{.Name}} {{.Version}}\n"
 
@@ -24,6 +30,18 @@ func info(c *cli.Context) error {
 		return err
 	}
 
+	output := &projectInfo{Config: project.Config, Packages: lockedSpecs(project.Locked)}
+	switch {
+	case isJSONFormat(c):
+		return printJSON(output)
+	case isTemplateFormat(c):
+		t, err := formatTemplate(c)
+		if err != nil {
+			return err
+		}
+		return printTemplate(os.Stdout, t, output)
+	}
+
 	// Prepare output template
 	t, err := template.New("info").Parse(infoFmt)
 	if err != nil {
@@ -31,5 +49,5 @@ func info(c *cli.Context) error {
 	}
 
 	// Print info to STDOUT
-	return t.Execute(os.Stdout, project.Config)
+	return t.Execute(os.Stdout, output)
 }
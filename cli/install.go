@@ -15,17 +15,25 @@ func install(c *cli.Context) error {
 	}
 
 	wDir, _ := os.Getwd()
-	return runInstall(wDir, nil)
+	return runInstall(c, wDir, nil)
 }
 
 // This helper will load project and lockfile, run any mutations that the user
 // specified via command line and run install (including saving the lockfile)
-func runInstall(dir string, mutate func(*project.Project) error) error {
+func runInstall(c *cli.Context, dir string, mutate func(*project.Project) error) error {
 	project, err := project.Load(dir)
 	log.Info("Project", project, " -- ", err)
 	if err != nil {
 		return err
 	}
+	configureProject(c, project)
+
+	events, file, err := openEvents(c)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	project.Events = events
 
 	// Perform mutation (add, remove, etc)
 	if mutate != nil {
@@ -35,5 +43,6 @@ func runInstall(dir string, mutate func(*project.Project) error) error {
 	}
 
 	// Convert Project.Config to Requested
-	return project.UpdateWithBase(project.Provider(), project.Locked)
+	err = project.UpdateWithBase(project.Provider(), project.Locked)
+	return formatError(c, err)
 }
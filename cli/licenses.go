@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/mdy/melody/project"
+	"github.com/urfave/cli"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+func licenses(c *cli.Context) error {
+	if len(c.Args()) != 0 {
+		return fmt.Errorf("`licenses` command takes no arguments. See '%s licenses --help'.", c.App.Name)
+	}
+
+	wDir, _ := os.Getwd()
+	proj, err := project.Load(wDir)
+	if err != nil {
+		return err
+	}
+
+	releases, err := proj.Licenses()
+	if err != nil {
+		return err
+	}
+
+	if isJSONFormat(c) {
+		output := struct {
+			Releases []*project.ReleaseLicenses `json:"releases"`
+		}{releases}
+		if err := printJSON(output); err != nil {
+			return err
+		}
+	} else {
+		printLicenses(releases)
+	}
+
+	if err := proj.CheckLicenses(); err != nil {
+		return cli.NewExitError("♫ "+err.Error(), 1)
+	}
+	return nil
+}
+
+func printLicenses(releases []*project.ReleaseLicenses) {
+	if len(releases) == 0 {
+		fmt.Println("♫ Simply no dependencies!")
+		return
+	}
+
+	fmt.Println("♫ Licenses of installed releases:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  PACKAGE\tVERSION\tLICENSE\tFILES")
+	for _, r := range releases {
+		licenses, files := strings.Join(r.Licenses, ", "), []string{}
+		for _, f := range r.Files {
+			files = append(files, f.Path)
+		}
+
+		if !r.Installed {
+			licenses = "(not installed)"
+		} else if licenses == "" {
+			licenses = "(none)"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", r.Name, r.Version, licenses, strings.Join(files, " "))
+	}
+	w.Flush()
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"github.com/mdy/melody/internal/extract"
 	"github.com/mdy/melody/project"
+	"github.com/mdy/melody/resolver"
 	"github.com/urfave/cli"
 	"go/build"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -46,6 +48,26 @@ func lint(c *cli.Context) error {
 		fmt.Printf("  Name is \"%s\", should be \"%s\"\n", a, e)
 	}
 
+	// Ranges that no version could ever satisfy
+	names := []string{}
+	for name := range proj.Config.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	source := proj.Provider()
+	for _, name := range names {
+		r := proj.Config.Dependencies[name]
+		req, ok := source.NewRequirement(name, r).(resolver.RangedRequirement)
+		if !ok {
+			continue
+		} else if set, err := req.VersionSet(); err != nil {
+			fmt.Printf("  Dependency \"%s\" has an invalid range \"%s\": %s\n", name, r, err)
+		} else if set.IsEmpty() {
+			fmt.Printf("  Dependency \"%s\" range \"%s\" can never be satisfied\n", name, r)
+		}
+	}
+
 	for d := range initConfig.Dependencies {
 		if _, ok := proj.Config.Dependencies[d]; !ok {
 			fmt.Printf("  Imported package \"%s\" should be a dependency\n", d)
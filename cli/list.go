@@ -3,7 +3,6 @@ package cli
 import (
 	"fmt"
 	"github.com/mdy/melody/project"
-	"github.com/mdy/melody/provider"
 	"github.com/urfave/cli"
 	"os"
 )
@@ -18,6 +17,7 @@ func list(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	configureProject(c, project)
 
 	// Resolve if not locked
 	if project.Locked == nil {
@@ -28,7 +28,25 @@ func list(c *cli.Context) error {
 		}
 	}
 
-	specs := project.Locked.Specifications()
+	specs := lockedSpecs(project.Locked)
+	switch {
+	case isJSONFormat(c):
+		return printJSON(struct {
+			Packages []*lockedSpec `json:"packages"`
+		}{specs})
+	case isTemplateFormat(c):
+		t, err := formatTemplate(c)
+		if err != nil {
+			return err
+		}
+		for _, s := range specs {
+			if err := printTemplate(os.Stdout, t, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	if len(specs) == 0 {
 		fmt.Println("♫ Simply no dependencies!")
 		return nil
@@ -36,9 +54,7 @@ func list(c *cli.Context) error {
 
 	fmt.Println("♫ Dependencies for this project:")
 	for _, s := range specs {
-		if _, isPkg := s.(provider.VersionSpec); isPkg {
-			fmt.Printf("  - %s\n", s.Name())
-		}
+		fmt.Printf("  - %s\n", s.Name)
 	}
 
 	return nil
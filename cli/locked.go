@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"github.com/mdy/melody/provider"
+	"github.com/mdy/melody/resolver"
+)
+
+// Locked package for JSON and template output
+type lockedSpec struct {
+	Name       string   `json:"name"`
+	Version    string   `json:"version"`
+	Release    string   `json:"release,omitempty"`
+	Revision   string   `json:"revision,omitempty"`
+	Branch     string   `json:"branch,omitempty"`
+	Source     string   `json:"source,omitempty"`
+	Direct     bool     `json:"direct"`
+	Dependents []string `json:"dependents"`
+}
+
+// Specifications that know where they are downloaded from
+type sourced interface {
+	Source() string
+}
+
+// Packages of a graph with their release and dependents, sorted by name
+func lockedSpecs(graph *resolver.Graph) []*lockedSpec {
+	roots := map[string]bool{}
+	for _, name := range graph.RootNames() {
+		roots[name] = true
+	}
+
+	specs := []*lockedSpec{}
+	for _, s := range graph.Specifications() {
+		ver, isPkg := s.(provider.VersionSpec)
+		if !isPkg {
+			continue
+		}
+
+		spec := &lockedSpec{
+			Name:       s.Name(),
+			Version:    s.Version(),
+			Direct:     roots[s.Name()],
+			Dependents: packagesOnly(graph.DependentsOf(s.Name())),
+		}
+		if release := ver.ReleaseSpec(); release != nil {
+			spec.Release = release.ExternalName()
+		}
+		if r, ok := s.(revisioned); ok && spec.Release != "" {
+			spec.Revision = r.Revision()
+		}
+		if b, ok := s.(provider.BranchSpec); ok {
+			spec.Branch = b.Branch()
+		}
+		if src, ok := s.(sourced); ok {
+			spec.Source = src.Source()
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/mdy/melody/project"
+	"testing"
+)
+
+// Project depending on github.com/a/x, which depends on the main branch of b/y
+const lockedLock = `
+[project]
+dependencies = ["github.com/a/x 1.0.0"]
+
+[[packages]]
+name = "github.com/a/x"
+version = "1.0.0"
+release = "github.com/a#aaaaaaaaaa"
+dependencies = ["github.com/b/y 2.0.0"]
+
+[[packages]]
+name = "github.com/b/y"
+version = "2.0.0"
+release = "github.com/b#bbbbbbbbbb"
+branch = "main"
+`
+
+func TestLockedSpecs(t *testing.T) {
+	graph, err := project.DecodeLockfile([]byte(lockedLock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	specs := lockedSpecs(graph)
+
+	// JSON shape that scripts rely on
+	raw, err := json.Marshal(specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[` +
+		`{"name":"github.com/a/x","version":"1.0.0","release":"github.com/a","revision":"aaaaaaaaaa",` +
+		`"source":"https://api.melody.sh/github.com/a/-/aaaaaaaaaa/tgz","direct":true,"dependents":[]},` +
+		`{"name":"github.com/b/y","version":"2.0.0","release":"github.com/b","revision":"bbbbbbbbbb","branch":"main",` +
+		`"source":"https://api.melody.sh/github.com/b/-/bbbbbbbbbb/tgz","direct":false,"dependents":["github.com/a/x"]}` +
+		`]`
+	if string(raw) != expected {
+		t.Errorf("lockedSpecs encoded as\n%s\nexpected\n%s", raw, expected)
+	}
+
+	tests := []struct {
+		template string
+		output   string
+	}{
+		{"{{.Name}} {{.Version}}", "github.com/a/x 1.0.0\ngithub.com/b/y 2.0.0\n"},
+		{"{{.Name}}:{{join .Dependents \",\"}}", "github.com/a/x:\ngithub.com/b/y:github.com/a/x\n"},
+		{"{{if .Direct}}{{.Release}}#{{.Revision}}{{else}}{{.Branch}}{{end}}", "github.com/a#aaaaaaaaaa\nmain\n"},
+	}
+
+	for _, test := range tests {
+		tmpl, err := parseTemplate(test.template)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out := &bytes.Buffer{}
+		for _, s := range specs {
+			if err := printTemplate(out, tmpl, s); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if out.String() != test.output {
+			t.Errorf("template %q printed %q, expected %q", test.template, out, test.output)
+		}
+	}
+
+	if _, err := parseTemplate("{{.Name"); err == nil {
+		t.Errorf("expected an error for an unterminated template")
+	}
+}
@@ -14,7 +14,7 @@ func addDependency(c *cli.Context) error {
 	}
 
 	wDir, _ := os.Getwd()
-	return runInstall(wDir, func(p *project.Project) error {
+	return runInstall(c, wDir, func(p *project.Project) error {
 		for _, pkgName := range c.Args() {
 			version := "" // Auto version, if unspecified
 			if i := strings.Index(pkgName, "@"); i >= 0 {
@@ -35,7 +35,7 @@ func removeDependency(c *cli.Context) error {
 	}
 
 	wDir, _ := os.Getwd()
-	return runInstall(wDir, func(p *project.Project) error {
+	return runInstall(c, wDir, func(p *project.Project) error {
 		for _, pkgName := range c.Args() {
 			if err := p.RemoveDependency(pkgName); err != nil {
 				return err
@@ -3,74 +3,293 @@ package cli
 import (
 	"github.com/mdy/melody/project"
 	"github.com/mdy/melody/provider"
+	"github.com/mdy/melody/resolver"
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/resolver/types"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"text/tabwriter"
 )
 
 func outdated(c *cli.Context) error {
+	if len(c.Args()) != 0 {
+		return fmt.Errorf("`outdated` command takes no arguments. See '%s outdated --help'.", c.App.Name)
+	}
+
 	wDir, _ := os.Getwd()
 	project, err := project.Load(wDir)
 	log.Info("Project", project, " -- ", err)
 	if err != nil {
 		return err
 	}
+	configureProject(c, project)
 
-	// Load or resolve current specs
+	// Load or resolve current specs, with the requirements between them
 	source := project.Provider()
-	if project.Locked == nil {
-		project.Locked, err = project.Resolve(source, nil)
-		if err != nil {
+	resolved := lockedGraph(c, project)
+
+	progress := io.Writer(os.Stdout)
+	if isStructuredFormat(c) {
+		progress = os.Stderr
+	}
+
+	outdated, err := findOutdated(source, project.Locked, resolved, progress)
+	if err != nil {
+		return err
+	}
+
+	releases := sortedOutdated(outdated, lockedSpecs(project.Locked))
+	switch {
+	case isJSONFormat(c):
+		err = printJSON(struct {
+			Releases []*outdatedRelease `json:"releases"`
+		}{releases})
+	case isTemplateFormat(c):
+		err = printOutdatedTemplate(c, releases)
+	default:
+		printOutdated(releases)
+	}
+
+	if err == nil && c.Bool("exit-code") && len(releases) > 0 {
+		msg := fmt.Sprintf("♫ %d outdated repositories", len(releases))
+		return cli.NewExitError(msg, 1)
+	}
+	return err
+}
+
+func printOutdated(releases []*outdatedRelease) {
+	if len(releases) == 0 {
+		fmt.Println("♫ All packages are up to date!")
+		return
+	}
+
+	fmt.Println("♫ Outdated repositories in the project:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  REPOSITORY\tCURRENT\tCOMPATIBLE\tLATEST\t")
+	for _, or := range releases {
+		compatible, note := or.Compatible, ""
+		if compatible == "" {
+			compatible = "?"
+		}
+		if or.Major {
+			note = "major update"
+		} else if or.Drift {
+			note = "new revision"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", or.Name, or.OldVersion, compatible, or.NewVersion, note)
+	}
+	w.Flush()
+}
+
+func printOutdatedTemplate(c *cli.Context, releases []*outdatedRelease) error {
+	t, err := formatTemplate(c)
+	if err != nil {
+		return err
+	}
+	for _, or := range releases {
+		if err := printTemplate(os.Stdout, t, or); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	fmt.Printf("♫ Resolving ...")
+// Outdated releases sorted by name, with their locked packages
+func sortedOutdated(outdated map[string]outdatedRelease, specs []*lockedSpec) []*outdatedRelease {
+	releases := []*outdatedRelease{}
+	for _, or := range outdated {
+		or := or
+		or.Packages = []*lockedSpec{}
+		for _, s := range specs {
+			if s.Release == or.Name {
+				or.Packages = append(or.Packages, s)
+			}
+		}
+		releases = append(releases, &or)
+	}
+
+	sort.Slice(releases, func(i, j int) bool { return releases[i].Name < releases[j].Name })
+	return releases
+}
+
+// Newer releases of locked packages by release name, with progress output.
+// Compatible versions are allowed by the requirements in the resolved graph
+func findOutdated(source provider.Provider, locked, resolved *resolver.Graph, w io.Writer) (map[string]outdatedRelease, error) {
+	fmt.Fprintf(w, "♫ Resolving ...")
 
 	// Check for new versions of all current specification
 	outdated := map[string]outdatedRelease{}
-	for _, oldSpec := range project.Locked.Specifications() {
-		if _, ok := oldSpec.(provider.ReleaseSpec); ok {
+	for _, oldSpec := range locked.Specifications() {
+		ver, isVer := oldSpec.(provider.VersionSpec)
+		if !isVer || ver.ReleaseSpec() == nil {
+			continue
+		}
+
+		fmt.Fprintf(w, ".")
+		if b, ok := oldSpec.(provider.BranchSpec); ok && b.Branch() != "" {
+			if err := outdatedBranch(source, b, outdated); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
-		fmt.Printf(".")
-		req := source.NewRequirement(oldSpec.Name(), "> "+oldSpec.Version())
-		specs := source.SearchFor(req)
-		if len(specs) == 0 {
+		or := outdatedPackage(source, ver, resolved)
+		if or == nil {
 			continue
 		}
 
-		if ver, isVer := specs[len(specs)-1].(provider.VersionSpec); isVer {
-			release := ver.ReleaseSpec()
-			outdated[release.Name()] = outdatedRelease{
-				Name:       release.ExternalName(),
-				OldVersion: oldSpec.Version(),
-				NewVersion: release.Version(),
+		// Packages of a release share its versions, the strictest wins
+		name := ver.ReleaseSpec().Name()
+		if prev, ok := outdated[name]; ok && !or.Drift {
+			or.Compatible = olderVersion(prev.Compatible, or.Compatible)
+		}
+		outdated[name] = *or
+	}
+	fmt.Fprintf(w, " done.\n")
+	return outdated, nil
+}
+
+// Newest and newest compatible versions of a package, or nil if it's up to
+// date.  Packages required at "head" can also move to a new revision
+func outdatedPackage(source provider.Provider, oldSpec provider.VersionSpec, resolved *resolver.Graph) *outdatedRelease {
+	or := &outdatedRelease{
+		Name:       oldSpec.ReleaseSpec().ExternalName(),
+		OldVersion: oldSpec.Version(),
+	}
+
+	reqs := resolved.RequirementsOn(oldSpec.Name())
+	newer := source.SearchFor(source.NewRequirement(oldSpec.Name(), "> "+oldSpec.Version()))
+	if len(newer) > 0 {
+		or.NewVersion = newer[len(newer)-1].Version()
+		or.Major = isMajorUpdate(or.OldVersion, or.NewVersion)
+		if len(reqs) > 0 {
+			or.Compatible = or.OldVersion
+		}
+		for i := len(newer) - 1; i >= 0 && len(reqs) > 0; i-- {
+			if satisfiesAll(source, resolved, reqs, newer[i]) {
+				or.Compatible = newer[i].Version()
+				break
 			}
 		}
+		return or
 	}
-	fmt.Printf(" done.\n")
 
-	if len(outdated) == 0 {
-		fmt.Println("♫ All packages are up to date!")
+	old, isRev := oldSpec.(revisioned)
+	if !isRev || !requiresHead(reqs) {
 		return nil
 	}
 
-	fmt.Println("♫ Outdated repositories in the project:")
-	for _, or := range outdated {
-		fmt.Printf("  * %s (newest %s, installed %s)\n",
-			or.Name, or.NewVersion, or.OldVersion,
-		)
+	// Same version at a different revision
+	heads := source.SearchFor(source.NewRequirement(oldSpec.Name(), "head"))
+	if len(heads) == 0 {
+		return nil
+	}
+	tip, ok := heads[len(heads)-1].(revisioned)
+	if !ok || tip.Revision() == "" || tip.Revision() == old.Revision() {
+		return nil
+	}
+
+	or.Drift = true
+	or.OldVersion = revisionVersion(oldSpec.Version(), old.Revision())
+	or.NewVersion = revisionVersion(heads[len(heads)-1].Version(), tip.Revision())
+	or.Compatible = or.NewVersion
+	return or
+}
+
+func satisfiesAll(source provider.Provider, graph *resolver.Graph, reqs types.Requirements, spec types.Specification) bool {
+	for _, req := range reqs {
+		if !source.IsRequirementSatisfiedBy(req, graph, spec) {
+			return false
+		}
+	}
+	return true
+}
+
+// Any requirement that follows the newest revision
+func requiresHead(reqs types.Requirements) bool {
+	for _, req := range reqs {
+		if c, ok := req.(resolver.ConstrainedRequirement); ok && c.Constraint() == "head" {
+			return true
+		}
+	}
+	return false
+}
+
+// New version breaks compatibility with the old one (1.x to 2.x)
+func isMajorUpdate(oldVersion, newVersion string) bool {
+	oldV, err := flex.VersionParser.Parse(oldVersion)
+	if err != nil {
+		return false
+	}
+	newV, err := flex.VersionParser.Parse(newVersion)
+	return err == nil && newV.Compare(oldV.MajorBump()) >= 0
+}
+
+// Lower of two versions, or unknown (empty) if either of them is unknown
+func olderVersion(a, b string) string {
+	if a == "" || b == "" {
+		return ""
+	}
+	va, errA := flex.VersionParser.Parse(a)
+	vb, errB := flex.VersionParser.Parse(b)
+	if errA == nil && errB == nil && vb.Compare(va) < 0 {
+		return b
 	}
+	return a
+}
 
+// Check whether the branch tracked by a spec has moved since it was locked
+func outdatedBranch(source provider.Provider, oldSpec provider.BranchSpec, outdated map[string]outdatedRelease) error {
+	bp, ok := source.(provider.BranchProvider)
+	if !ok {
+		return nil
+	}
+
+	tip, err := bp.BranchTip(oldSpec.Name(), oldSpec.Branch())
+	if err != nil {
+		return err
+	}
+
+	newSpec, isBranch := tip.(provider.BranchSpec)
+	ver, isVer := tip.(provider.VersionSpec)
+	if !isBranch || !isVer || newSpec.Revision() == oldSpec.Revision() {
+		return nil
+	}
+
+	release := ver.ReleaseSpec()
+	outdated[release.Name()] = outdatedRelease{
+		Name:       release.ExternalName(),
+		OldVersion: branchVersion(oldSpec),
+		Compatible: branchVersion(newSpec),
+		NewVersion: branchVersion(newSpec),
+		Drift:      true,
+	}
 	return nil
 }
 
+// Branch and abbreviated revision (release-2.x@1a2b3c4)
+func branchVersion(spec provider.BranchSpec) string {
+	return revisionVersion(spec.Branch(), spec.Revision())
+}
+
+// Version and abbreviated revision (1.2.0@1a2b3c4)
+func revisionVersion(version, revision string) string {
+	return version + "@" + shortRevision(revision)
+}
+
+// Installed release and the newest versions allowed by the requirements
+// (compatible, empty if unknown) and without requirements (newest)
 type outdatedRelease struct {
-	Name       string
-	NewVersion string
-	OldVersion string
+	Name       string        `json:"name"`
+	OldVersion string        `json:"installed"`
+	Compatible string        `json:"compatible,omitempty"`
+	NewVersion string        `json:"newest"`
+	Major      bool          `json:"major"`
+	Drift      bool          `json:"revisionDrift"`
+	Packages   []*lockedSpec `json:"packages"`
 }
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"github.com/mdy/melody/internal/testindex"
+	"github.com/mdy/melody/resolver"
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/resolver/types"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestOutdatedPackage(t *testing.T) {
+	source := testindex.New(
+		"app 1.0.0#a1 lib >=1.0 dev head",
+		"tool 1.0.0#t1 lib <1.2 pin head",
+		"lib 1.0.0#l1",
+		"lib 1.1.0#l2",
+		"lib 1.2.0#l3",
+		"lib 2.0.0#l4",
+		"dev 0.1.0#1111111111",
+		"dev 0.1.0#2222222222",
+		"pin 0.3.0#3333333333",
+	)
+
+	requested := []types.Requirement{flex.NewDependency("app", "1.0.0"), flex.NewDependency("tool", "1.0.0")}
+	res := resolver.NewResolver(source, resolver.NewWriterUI(ioutil.Discard))
+	resolved, err := res.Resolve(requested, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, revision string
+		graph          *resolver.Graph
+		expected       *outdatedRelease
+	}{
+		// Newest version allowed by every dependent, and a major update
+		{"lib", "l1", resolved, &outdatedRelease{
+			Name: "lib", OldVersion: "1.0.0", Compatible: "1.1.0", NewVersion: "2.0.0", Major: true,
+		}},
+
+		// Minor updates that no dependent allows stay at the current version
+		{"lib", "l2", resolved, &outdatedRelease{
+			Name: "lib", OldVersion: "1.1.0", Compatible: "1.1.0", NewVersion: "2.0.0", Major: true,
+		}},
+		{"lib", "l3", resolved, &outdatedRelease{
+			Name: "lib", OldVersion: "1.2.0", Compatible: "1.2.0", NewVersion: "2.0.0", Major: true,
+		}},
+
+		// Without requirements (Melody.lock), the compatible version is unknown
+		{"lib", "l1", resolver.NewGraph(), &outdatedRelease{
+			Name: "lib", OldVersion: "1.0.0", NewVersion: "2.0.0", Major: true,
+		}},
+
+		// Packages required at head drift to a new revision
+		{"dev", "1111111111", resolved, &outdatedRelease{
+			Name: "dev", OldVersion: "0.1.0@1111111", Compatible: "0.1.0@2222222", NewVersion: "0.1.0@2222222", Drift: true,
+		}},
+
+		// Up to date
+		{"lib", "l4", resolved, nil},
+		{"dev", "2222222222", resolved, nil},
+		{"pin", "3333333333", resolved, nil},
+		{"dev", "1111111111", resolver.NewGraph(), nil},
+	}
+
+	for _, test := range tests {
+		or := outdatedPackage(source, source.Revision(test.name, test.revision), test.graph)
+		if !reflect.DeepEqual(or, test.expected) {
+			t.Errorf("%s#%s: outdated %+v, expected %+v", test.name, test.revision, or, test.expected)
+		}
+	}
+}
+
+func TestFindOutdated_MergedRelease(t *testing.T) {
+	source := testindex.New(
+		"app 1.0.0 lib >=1.0 lib/sub <1.1",
+		"lib 1.0.0",
+		"lib 1.1.0",
+		"lib/sub 1.0.0",
+		"lib/sub 1.1.0",
+		"lib/util 1.0.0",
+		"lib/util 1.1.0",
+	)
+
+	resolve := func(reqs ...types.Requirement) *resolver.Graph {
+		res := resolver.NewResolver(source, resolver.NewWriterUI(ioutil.Discard))
+		graph, err := res.Resolve(reqs, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return graph
+	}
+
+	// Only "lib" allows 1.1.0, and nothing requires "lib/util"
+	resolved := resolve(flex.NewDependency("app", "1.0.0"))
+	tests := []struct {
+		packages   []string
+		compatible string
+	}{
+		{[]string{"lib", "lib/sub"}, "1.0.0"},
+		{[]string{"lib", "lib/util"}, ""},
+		{[]string{"lib", "lib/sub", "lib/util"}, ""},
+	}
+
+	for _, test := range tests {
+		reqs := []types.Requirement{}
+		for _, name := range test.packages {
+			reqs = append(reqs, flex.NewDependency(name, "1.0.0"))
+		}
+
+		outdated, err := findOutdated(source, resolve(reqs...), resolved, ioutil.Discard)
+		if err != nil {
+			t.Fatal(err)
+		} else if or, ok := outdated["repo://lib"]; !ok {
+			t.Errorf("%v: lib is not outdated", test.packages)
+		} else if or.Compatible != test.compatible {
+			t.Errorf("%v: compatible %q, expected %q", test.packages, or.Compatible, test.compatible)
+		}
+	}
+}
+
+func TestIsMajorUpdate(t *testing.T) {
+	tests := []struct {
+		old, new string
+		major    bool
+	}{
+		{"1.0.0", "1.9.9", false},
+		{"1.0.0", "2.0.0", true},
+		{"1.4.0", "3.1.0", true},
+		{"0.1.0", "0.2.0", false},
+		{"0.1.0", "1.0.0", true},
+		{"0.1.0", "0.1.5", false},
+		{"1.0.0", "2.0.0-beta", false},
+	}
+
+	for _, test := range tests {
+		if major := isMajorUpdate(test.old, test.new); major != test.major {
+			t.Errorf("isMajorUpdate(%s, %s) = %v, expected %v", test.old, test.new, major, test.major)
+		}
+	}
+}
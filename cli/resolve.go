@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/mdy/melody/project"
+	"github.com/mdy/melody/resolver"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"os"
+)
+
+func resolve(c *cli.Context) error {
+	if len(c.Args()) != 0 {
+		return fmt.Errorf("`resolve` command takes no arguments. See '%s resolve --help'.", c.App.Name)
+	}
+
+	if path := c.String("replay"); path != "" {
+		return replaySession(c, path)
+	}
+
+	wDir, _ := os.Getwd()
+	project, err := project.Load(wDir)
+	log.Info("Project", project, " -- ", err)
+	if err != nil {
+		return err
+	}
+	configureProject(c, project)
+
+	events, file, err := openEvents(c)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	project.Events = events
+
+	out, err := project.Resolve(project.Provider(), project.Locked)
+	if err != nil {
+		return formatError(c, err)
+	}
+
+	printResolved(out)
+	return nil
+}
+
+// Re-run resolution against a recorded session (no network access)
+func replaySession(c *cli.Context, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	session, err := resolver.DecodeSession(file)
+	if err != nil {
+		return err
+	}
+
+	strategy, err := resolver.ParseStrategy(session.Strategy)
+	if err != nil {
+		return err
+	}
+
+	res := resolver.NewResolver(session.Provider(), newUI(c))
+	res.Strategy = strategy
+	res.Overrides = session.RecordedOverrides()
+
+	events, eventsFile, err := openEvents(c)
+	if err != nil {
+		return err
+	}
+	defer eventsFile.Close()
+	res.Events = events
+
+	out, err := res.Resolve(session.RequestedRequirements(), session.BaseGraph())
+	if err != nil {
+		return formatError(c, err)
+	}
+
+	printResolved(out)
+	return nil
+}
+
+func printResolved(graph *resolver.Graph) {
+	fmt.Println("♫ Resolved specifications:")
+	for _, s := range graph.Specifications() {
+		fmt.Printf("  - %s %s\n", s.Name(), s.Version())
+	}
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/mdy/melody/project"
+	"github.com/mdy/melody/provider"
+	"github.com/mdy/melody/resolver"
+	"github.com/urfave/cli"
+	"io"
+	"os"
+	"strings"
+)
+
+func tree(c *cli.Context) error {
+	if len(c.Args()) != 0 {
+		return fmt.Errorf("`tree` command takes no arguments. See '%s tree --help'.", c.App.Name)
+	}
+
+	wDir, _ := os.Getwd()
+	proj, err := project.Load(wDir)
+	if err != nil {
+		return err
+	}
+
+	return printTree(os.Stdout, proj.Config.Name, proj.Locked, c.String("invert"))
+}
+
+// Tree of locked packages from the roots, or of the packages depending on
+// the inverted one
+func printTree(out io.Writer, title string, graph *resolver.Graph, invert string) error {
+	t := &treePrinter{out: out, graph: graph, seen: map[string]bool{}, roots: map[string]bool{}}
+	t.children = func(name string) []string { return packagesOnly(graph.DependenciesOf(name)) }
+
+	if invert != "" {
+		if graph.PayloadFor(invert) == nil {
+			return fmt.Errorf("%s is not in Melody.lock", invert)
+		}
+		t.children = func(name string) []string { return packagesOnly(graph.DependentsOf(name)) }
+		for _, root := range graph.RootNames() {
+			t.roots[root] = true
+		}
+		fmt.Fprintf(out, "♫ %s\n", t.label(invert))
+		t.print(t.children(invert), "")
+		return nil
+	}
+
+	fmt.Fprintf(out, "♫ %s\n", title)
+	t.print(packagesOnly(graph.RootNames()), "")
+	return nil
+}
+
+// Tree of packages where every subtree is only shown once
+type treePrinter struct {
+	out      io.Writer
+	graph    *resolver.Graph
+	children func(string) []string
+	seen     map[string]bool
+	roots    map[string]bool // Marked as Melody.toml dependencies
+}
+
+func (t *treePrinter) print(names []string, prefix string) {
+	for i, name := range names {
+		branch, indent := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		children := t.children(name)
+		if t.seen[name] && len(children) > 0 {
+			fmt.Fprintf(t.out, "%s%s%s (*)\n", prefix, branch, t.label(name))
+			continue
+		}
+
+		t.seen[name] = true
+		fmt.Fprintf(t.out, "%s%s%s\n", prefix, branch, t.label(name))
+		t.print(children, prefix+indent)
+	}
+}
+
+// Package name, locked version and release revision
+func (t *treePrinter) label(name string) string {
+	spec := t.graph.PayloadFor(name)
+	if spec == nil {
+		return name
+	}
+
+	label := name + " " + spec.Version()
+	if ver, ok := spec.(provider.VersionSpec); ok && ver.ReleaseSpec() != nil {
+		if r, ok := spec.(revisioned); ok && r.Revision() != "" {
+			label += " #" + shortRevision(r.Revision())
+		}
+	}
+	if t.roots[name] {
+		label += " (Melody.toml)"
+	}
+	return label
+}
+
+// Specifications that know their revision
+type revisioned interface {
+	Revision() string
+}
+
+// Releases are part of their packages, so they're not shown on their own
+func packagesOnly(names []string) []string {
+	out := []string{}
+	for _, name := range names {
+		if !strings.HasPrefix(name, "repo://") {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func shortRevision(rev string) string {
+	if len(rev) > 7 {
+		return rev[:7]
+	}
+	return rev
+}
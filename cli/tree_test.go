@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/mdy/melody/project"
+	"strings"
+	"testing"
+)
+
+// Project depending on github.com/a/x and github.com/b/z, where a/x and b/z
+// share b/y, and b/y depends on a/y from a/x's repository
+const treeLock = `
+[project]
+dependencies = ["github.com/a/x 1.0.0", "github.com/b/z 2.0.0"]
+
+[[packages]]
+name = "github.com/a/x"
+version = "1.0.0"
+release = "github.com/a#aaaaaaaaaa"
+dependencies = ["github.com/b/y 2.0.0"]
+
+[[packages]]
+name = "github.com/a/y"
+version = "1.0.0"
+release = "github.com/a#aaaaaaaaaa"
+
+[[packages]]
+name = "github.com/b/y"
+version = "2.0.0"
+release = "github.com/b#bbbbbbbbbb"
+dependencies = ["github.com/a/y 1.0.0"]
+
+[[packages]]
+name = "github.com/b/z"
+version = "2.0.0"
+release = "github.com/b#bbbbbbbbbb"
+dependencies = ["github.com/b/y 2.0.0"]
+`
+
+func TestPrintTree(t *testing.T) {
+	graph, err := project.DecodeLockfile([]byte(treeLock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		invert string
+		tree   []string
+	}{
+		// Shared subtrees are only shown once, and repositories are skipped
+		// even though a and b depend on each other
+		{"", []string{
+			"♫ app",
+			"├── github.com/a/x 1.0.0 #aaaaaaa",
+			"│   └── github.com/b/y 2.0.0 #bbbbbbb",
+			"│       └── github.com/a/y 1.0.0 #aaaaaaa",
+			"└── github.com/b/z 2.0.0 #bbbbbbb",
+			"    └── github.com/b/y 2.0.0 #bbbbbbb (*)",
+		}},
+
+		// Inverted trees lead to the Melody.toml dependencies
+		{"github.com/a/y", []string{
+			"♫ github.com/a/y 1.0.0 #aaaaaaa",
+			"└── github.com/b/y 2.0.0 #bbbbbbb",
+			"    ├── github.com/a/x 1.0.0 #aaaaaaa (Melody.toml)",
+			"    └── github.com/b/z 2.0.0 #bbbbbbb (Melody.toml)",
+		}},
+	}
+
+	for _, test := range tests {
+		out := &bytes.Buffer{}
+		if err := printTree(out, "app", graph, test.invert); err != nil {
+			t.Fatal(err)
+		}
+		if tree := strings.Join(test.tree, "\n") + "\n"; out.String() != tree {
+			t.Errorf("printTree(%q) printed\n%s\nexpected\n%s", test.invert, out, tree)
+		}
+	}
+
+	if err := printTree(&bytes.Buffer{}, "app", graph, "github.com/c/missing"); err == nil {
+		t.Errorf("expected an error when inverting a package that's not locked")
+	}
+}
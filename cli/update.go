@@ -5,9 +5,11 @@ import (
 	"github.com/mdy/melody/project"
 	"github.com/mdy/melody/provider"
 	"github.com/mdy/melody/resolver"
+	"github.com/mdy/melody/resolver/flex"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 
+	"fmt"
 	"os"
 	"strings"
 )
@@ -19,9 +21,20 @@ func update(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	configureProject(c, project)
+
+	events, file, err := openEvents(c)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	project.Events = events
+	locked := project.Locked
 
 	var baseGraph *resolver.Graph
-	if len(c.Args()) == 0 {
+	if len(c.Args()) == 0 && c.Bool("conservative") {
+		return fmt.Errorf("`update --conservative` needs packages to update. See '%s update --help'.", c.App.Name)
+	} else if len(c.Args()) == 0 {
 		baseGraph = resolver.NewGraph()
 	} else {
 		g, err := glob.Compile("{" + strings.Join(c.Args(), ",") + "}")
@@ -29,6 +42,33 @@ func update(c *cli.Context) error {
 			return err
 		}
 
+		// Try to update only the matched packages
+		if c.Bool("conservative") {
+			names := []string{}
+			for _, spec := range project.Locked.Specifications() {
+				if vs, ok := spec.(provider.VersionSpec); ok {
+					if rs := vs.ReleaseSpec(); g.Match(rs.ExternalName()) {
+						names = append(names, spec.Name(), rs.Name())
+					}
+				}
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("No locked package matches %s", strings.Join(c.Args(), ", "))
+			}
+
+			moved, err := project.UpdateConservatively(project.Provider(), names)
+			if err != nil {
+				return formatError(c, err)
+			}
+
+			diff := locked.Diff(project.Locked, flex.VersionParser)
+			if isJSONFormat(c) {
+				return printJSON(&conservativeDiff{diff, moved})
+			}
+			printMovedPackages(moved)
+			return printLockDiff(c, diff)
+		}
+
 		baseGraph = project.Locked.Dup()
 		for _, spec := range project.Locked.Specifications() {
 			if vs, ok := spec.(provider.VersionSpec); ok {
@@ -40,5 +80,47 @@ func update(c *cli.Context) error {
 	}
 
 	// Convert Project.Config to Requested
-	return project.UpdateWithBase(project.Provider(), baseGraph)
+	err = project.UpdateWithBase(project.Provider(), baseGraph)
+	if err != nil {
+		return formatError(c, err)
+	}
+	return printLockDiff(c, locked.Diff(project.Locked, flex.VersionParser))
+}
+
+// Summary of changes to Melody.lock, by kind of change
+func printLockDiff(c *cli.Context, diff *resolver.GraphDiff) error {
+	if isJSONFormat(c) {
+		return printJSON(diff)
+	}
+
+	if diff.IsEmpty() {
+		fmt.Println("♫ Melody.lock is unchanged")
+		return nil
+	}
+
+	if c.Bool("dry-run") {
+		fmt.Println("♫ Changes to Melody.lock (dry run, nothing written):")
+	} else {
+		fmt.Println("♫ Changes to Melody.lock:")
+	}
+	printGraphDiff(diff)
+	return nil
+}
+
+// Changes to Melody.lock with the extra packages a conservative update moved
+type conservativeDiff struct {
+	*resolver.GraphDiff
+	Unlocked []*project.Unlocked `json:"unlocked"`
+}
+
+// Report extra packages that were updated by a conservative update
+func printMovedPackages(moved []*project.Unlocked) {
+	if len(moved) == 0 {
+		return
+	}
+
+	fmt.Println("♫ Additional packages had to be updated:")
+	for _, m := range moved {
+		fmt.Printf("  * %s %s => %s (%s)\n", m.Name, m.OldVersion, m.NewVersion, m.Reason)
+	}
 }
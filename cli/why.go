@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/mdy/melody/project"
+	"github.com/mdy/melody/resolver"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"os"
+	"strings"
+)
+
+func why(c *cli.Context) error {
+	if len(c.Args()) != 1 {
+		return fmt.Errorf("`why` command takes one package name. See '%s why --help'.", c.App.Name)
+	}
+
+	wDir, _ := os.Getwd()
+	proj, err := project.Load(wDir)
+	if err != nil {
+		return err
+	}
+	configureProject(c, proj)
+
+	name := c.Args().First()
+	if proj.Locked.PayloadFor(name) == nil {
+		return fmt.Errorf("%s is not in Melody.lock", name)
+	}
+
+	graph := lockedGraph(c, proj)
+	if graph.PayloadFor(name) == nil {
+		graph = proj.Locked
+	}
+
+	spec := graph.PayloadFor(name)
+	fmt.Printf("♫ %s %s is in the project because of:\n", name, spec.Version())
+	for _, path := range graph.PathsTo(name) {
+		root := path[0].Vertex.Name
+		if r, ok := proj.Config.Dependencies[root]; ok {
+			fmt.Printf("  * Melody.toml: \"%s\" = \"%s\"\n", root, r)
+		} else {
+			fmt.Printf("  * %s (not in Melody.toml)\n", root)
+		}
+
+		for i, step := range path {
+			indent := strings.Repeat("  ", i)
+			fmt.Printf("      %s%s %s (%s)\n", indent, step.Vertex.Name,
+				step.Vertex.Payload.Version(), stepConstraint(step))
+		}
+	}
+	return nil
+}
+
+// Melody.lock has no requirements, so resolve again with every version
+// locked to get them, or use it without requirements if that fails
+func lockedGraph(c *cli.Context, proj *project.Project) *resolver.Graph {
+	if c.Bool("locked") {
+		log.Info("Using Melody.lock without requirements")
+		return proj.Locked
+	}
+
+	resolved, err := proj.Resolve(proj.Provider(), proj.Locked)
+	if err != nil {
+		log.Warn("Could not resolve requirements: ", err)
+		return proj.Locked
+	}
+	return resolved
+}
+
+func stepConstraint(step *resolver.PathStep) string {
+	if step.Requirement == nil {
+		return "unknown requirement"
+	} else if c, ok := step.Requirement.(resolver.ConstrainedRequirement); ok {
+		return c.Constraint()
+	}
+	return step.Requirement.String()
+}
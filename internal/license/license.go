@@ -0,0 +1,202 @@
+package license
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Identifier for license text that does not match any known license
+const Unknown = "unknown"
+
+// License families that can be used in a policy instead of SPDX identifiers
+const (
+	Permissive   = "permissive"
+	WeakCopyleft = "weak-copyleft"
+	Copyleft     = "copyleft"
+)
+
+// License, copying or notice file in a release directory
+type File struct {
+	Path   string `json:"path"`
+	ID     string `json:"id,omitempty"` // Empty for notices
+	Notice bool   `json:"notice,omitempty"`
+}
+
+// SPDX license matched by phrases from its text.  Licenses that mention
+// other licenses have to come first (MPL lists the GPL as compatible), and
+// GPL family texts mention each other, so they're matched by their title
+// before the notices that only refer to them
+type known struct {
+	id      string
+	family  string
+	phrases []string
+	unless  []string
+}
+
+var knownLicenses = []known{
+	{"MPL-2.0", WeakCopyleft, []string{"mozilla public license version 2.0"}, nil},
+	{"EPL-2.0", WeakCopyleft, []string{"eclipse public license", "2.0"}, nil},
+	{"AGPL-3.0", Copyleft, []string{"gnu affero general public license version 3"}, nil},
+	{"LGPL-3.0", WeakCopyleft, []string{"gnu lesser general public license version 3"}, nil},
+	{"LGPL-2.1", WeakCopyleft, []string{"gnu lesser general public license version 2.1"}, nil},
+	{"GPL-3.0", Copyleft, []string{"gnu general public license version 3"}, nil},
+	{"GPL-2.0", Copyleft, []string{"gnu general public license version 2"}, nil},
+	{"AGPL-3.0", Copyleft, []string{"gnu affero general public license", "either version 3"}, nil},
+	{"LGPL-3.0", WeakCopyleft, []string{"gnu lesser general public license", "either version 3"}, nil},
+	{"LGPL-2.1", WeakCopyleft, []string{"gnu lesser general public license", "either version 2.1"}, nil},
+	{"GPL-3.0", Copyleft, []string{"gnu general public license", "either version 3"}, nil},
+	{"GPL-2.0", Copyleft, []string{"gnu general public license", "either version 2"}, nil},
+	{"Apache-2.0", Permissive, []string{"apache license", "version 2.0"}, nil},
+	{"BSL-1.0", Permissive, []string{"boost software license version 1.0"}, nil},
+	{"BSD-3-Clause", Permissive, []string{"redistribution and use in source and binary forms", "neither the name"}, nil},
+	{"BSD-2-Clause", Permissive, []string{"redistribution and use in source and binary forms"}, []string{"neither the name"}},
+	{"ISC", Permissive, []string{"permission to use copy modify and or distribute this software for any purpose"}, nil},
+	{"MIT", Permissive, []string{"permission is hereby granted free of charge to any person obtaining a copy"}, nil},
+	{"Unlicense", Permissive, []string{"this is free and unencumbered software released into the public domain"}, nil},
+	{"CC0-1.0", Permissive, []string{"cc0 1.0 universal"}, nil},
+}
+
+// Names of files with license information (LICENSE.md, COPYING, NOTICE.txt)
+var (
+	licenseFileRE = regexp.MustCompile(`(?i)^(licen[cs]e|copying)([-_.].*)?$`)
+	noticeFileRE  = regexp.MustCompile(`(?i)^notice([-_.].*)?$`)
+	nonWordRE     = regexp.MustCompile(`[^a-z0-9.]+`)
+)
+
+// Find and classify license files at the top of a release directory
+func Scan(dir string) ([]*File, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []*File{}
+	for _, entry := range entries {
+		name := entry.Name()
+		isLicense, isNotice := licenseFileRE.MatchString(name), noticeFileRE.MatchString(name)
+		if entry.IsDir() || !(isLicense || isNotice) {
+			continue
+		}
+
+		file := &File{Path: name, Notice: isNotice}
+		if isLicense {
+			text, err := ioutil.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			file.ID = Classify(string(text))
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// SPDX identifier for a license text, or Unknown
+func Classify(text string) string {
+	normalized := normalize(text)
+	for _, l := range knownLicenses {
+		if containsAll(normalized, l.phrases) && !containsAny(normalized, l.unless) {
+			return l.id
+		}
+	}
+	return Unknown
+}
+
+// Family of an SPDX identifier, empty if it's not known
+func Family(id string) string {
+	for _, l := range knownLicenses {
+		if l.id == id {
+			return l.family
+		}
+	}
+	return ""
+}
+
+// Lowercase words separated by single spaces, so that line wrapping
+// and punctuation don't matter
+func normalize(text string) string {
+	words := strings.Fields(nonWordRE.ReplaceAllString(strings.ToLower(text), " "))
+	for i, word := range words {
+		words[i] = strings.Trim(word, ".") // Keep dots in versions only
+	}
+	return " " + strings.Join(words, " ") + " "
+}
+
+func containsAll(text string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if !strings.Contains(text, " "+phrase+" ") {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(text string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(text, " "+phrase+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Unique license identifiers of files, without notices
+func IDs(files []*File) []string {
+	seen, ids := map[string]bool{}, []string{}
+	for _, f := range files {
+		if !f.Notice && !seen[f.ID] {
+			seen[f.ID] = true
+			ids = append(ids, f.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Allowed and denied licenses, by SPDX identifier or family
+type Policy struct {
+	Allow []string `toml:"allow" json:"allow,omitempty"`
+	Deny  []string `toml:"deny" json:"deny,omitempty"`
+}
+
+func (p *Policy) IsEmpty() bool {
+	return len(p.Allow) == 0 && len(p.Deny) == 0
+}
+
+// Reason why a package with these licenses violates the policy, or nil.
+// Denied licenses always violate, and when there's an allow list at least
+// one of the licenses must be on it
+func (p *Policy) Check(ids []string) error {
+	for _, id := range ids {
+		if matches(p.Deny, id) {
+			return fmt.Errorf("license %s is denied", id)
+		}
+	}
+
+	if len(p.Allow) == 0 {
+		return nil
+	} else if len(ids) == 0 {
+		return fmt.Errorf("no license found")
+	}
+
+	for _, id := range ids {
+		if matches(p.Allow, id) {
+			return nil
+		}
+	}
+	return fmt.Errorf("license %s is not allowed", strings.Join(ids, ", "))
+}
+
+// Check if an identifier is in a list of identifiers and families
+func matches(list []string, id string) bool {
+	for _, entry := range list {
+		if strings.EqualFold(entry, id) || (Family(id) != "" && strings.EqualFold(entry, Family(id))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package license
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		file string
+		id   string
+	}{
+		{"MIT.txt", "MIT"},
+		{"ISC.txt", "ISC"},
+		{"Unlicense.txt", "Unlicense"},
+		{"Apache-2.0.txt", "Apache-2.0"},
+		{"BSD-3-Clause.txt", "BSD-3-Clause"},
+		{"BSD-2-Clause.txt", "BSD-2-Clause"},
+
+		// Texts that mention other licenses of the family
+		{"MPL-2.0.txt", "MPL-2.0"},
+		{"AGPL-3.0.txt", "AGPL-3.0"},
+		{"GPL-3.0.txt", "GPL-3.0"},
+		{"GPL-2.0.txt", "GPL-2.0"},
+		{"LGPL-3.0.txt", "LGPL-3.0"},
+		{"LGPL-2.1.txt", "LGPL-2.1"},
+
+		// Notices that only refer to a license
+		{"GPL-3.0-notice.txt", "GPL-3.0"},
+		{"LGPL-3.0-notice.txt", "LGPL-3.0"},
+		{"unknown.txt", Unknown},
+	}
+
+	for _, test := range tests {
+		text, err := ioutil.ReadFile(filepath.Join("testdata", test.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id := Classify(string(text)); id != test.id {
+			t.Errorf("Classify(%s) = %s, expected %s", test.file, id, test.id)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "license")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mit, _ := ioutil.ReadFile(filepath.Join("testdata", "MIT.txt"))
+	for name, text := range map[string][]byte{"LICENSE.md": mit, "NOTICE": []byte("Notice"), "main.go": nil} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), text, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*File{{Path: "LICENSE.md", ID: "MIT"}, {Path: "NOTICE", Notice: true}}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Scan found %v, expected %v", files, expected)
+	}
+	if ids := IDs(files); !reflect.DeepEqual(ids, []string{"MIT"}) {
+		t.Errorf("IDs = %v, expected [MIT]", ids)
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	tests := []struct {
+		policy Policy
+		ids    []string
+		err    string
+	}{
+		{Policy{}, []string{"GPL-3.0"}, ""},
+		{Policy{}, []string{}, ""},
+
+		// Denied identifiers or families always violate
+		{Policy{Deny: []string{"GPL-3.0"}}, []string{"GPL-3.0"}, "license GPL-3.0 is denied"},
+		{Policy{Deny: []string{"copyleft"}}, []string{"MIT", "AGPL-3.0"}, "license AGPL-3.0 is denied"},
+		{Policy{Deny: []string{"copyleft"}}, []string{"LGPL-2.1"}, ""},
+		{Policy{Deny: []string{"gpl-2.0"}}, []string{"GPL-2.0"}, "license GPL-2.0 is denied"},
+
+		// One license must be allowed, if there's an allow list
+		{Policy{Allow: []string{"permissive"}}, []string{"BSD-2-Clause"}, ""},
+		{Policy{Allow: []string{"permissive"}}, []string{"GPL-2.0", "MIT"}, ""},
+		{Policy{Allow: []string{"MIT", "weak-copyleft"}}, []string{"MPL-2.0"}, ""},
+		{Policy{Allow: []string{"permissive"}}, []string{"LGPL-3.0"}, "license LGPL-3.0 is not allowed"},
+		{Policy{Allow: []string{"permissive"}}, []string{Unknown}, "license unknown is not allowed"},
+		{Policy{Allow: []string{"permissive"}}, []string{}, "no license found"},
+		{Policy{Allow: []string{"permissive"}, Deny: []string{"ISC"}}, []string{"ISC"}, "license ISC is denied"},
+	}
+
+	for _, test := range tests {
+		err := test.policy.Check(test.ids)
+		if test.err == "" && err != nil {
+			t.Errorf("%+v.Check(%v): unexpected error %s", test.policy, test.ids, err)
+		} else if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("%+v.Check(%v) = %v, expected %q", test.policy, test.ids, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package osv
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Metric weights of CVSS v3 base scores (https://www.first.org/cvss/v3.1/specification-document)
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// Privileges required weigh more when the scope changes
+var cvss3ChangedPR = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+
+// Base score of a CVSS v3 vector, like "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+func CVSS3Score(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, fmt.Errorf("Not a CVSS v3 vector: %q", vector)
+	}
+
+	metrics := map[string]string{}
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			return 0, fmt.Errorf("Invalid CVSS metric %q in %q", part, vector)
+		}
+		metrics[kv[0]] = kv[1]
+	}
+
+	changed := metrics["S"] == "C"
+	if s := metrics["S"]; s != "U" && s != "C" {
+		return 0, fmt.Errorf("Invalid CVSS scope %q in %q", s, vector)
+	}
+
+	w := map[string]float64{}
+	for metric, values := range cvss3Weights {
+		value, ok := values[metrics[metric]]
+		if !ok {
+			return 0, fmt.Errorf("Invalid CVSS metric %s:%s in %q", metric, metrics[metric], vector)
+		}
+		w[metric] = value
+	}
+	if changed {
+		w["PR"] = cvss3ChangedPR[metrics["PR"]]
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+
+	switch {
+	case impact <= 0:
+		return 0, nil
+	case changed:
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	default:
+		return roundUp(math.Min(impact+exploitability, 10)), nil
+	}
+}
+
+// Smallest number with one decimal that's equal or higher, without
+// floating point errors (CVSS v3.1 Roundup)
+func roundUp(f float64) float64 {
+	i := int(math.Round(f * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
@@ -0,0 +1,276 @@
+package osv
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdy/melody/version"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Vulnerability record in OSV JSON format (https://ossf.github.io/osv-schema/)
+type Vulnerability struct {
+	ID               string          `json:"id"`
+	Summary          string          `json:"summary"`
+	Aliases          []string        `json:"aliases"`
+	Severity         []SeverityScore `json:"severity"`
+	Affected         []Affected      `json:"affected"`
+	DatabaseSeverity string          `json:"-"`
+}
+
+type SeverityScore struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// Package versions affected by a vulnerability
+type Affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []AffectedRange `json:"ranges"`
+	Versions []string        `json:"versions"`
+}
+
+// Events of a range, in the order they happened
+type AffectedRange struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// Severity levels in increasing order
+type Severity int
+
+const (
+	Low      Severity = iota + 1 // Low impact
+	Moderate                     // Medium impact
+	High                         // High impact
+	Critical                     // Critical impact
+)
+
+var severityNames = map[string]Severity{
+	"low":      Low,
+	"moderate": Moderate,
+	"medium":   Moderate,
+	"high":     High,
+	"critical": Critical,
+}
+
+func ParseSeverity(name string) (Severity, error) {
+	if s, ok := severityNames[strings.ToLower(name)]; ok {
+		return s, nil
+	}
+	return 0, fmt.Errorf("Unknown severity %q (low, moderate, high or critical)", name)
+}
+
+func (s Severity) String() string {
+	switch s {
+	case Low:
+		return "low"
+	case Moderate:
+		return "moderate"
+	case High:
+		return "high"
+	case Critical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// Severity from the database specific level (GitHub advisories), or from
+// the base score of a CVSS v3 vector.  CVSS v2 vectors aren't read, and
+// unknown severities are reported as critical
+func (v *Vulnerability) Level() Severity {
+	if s, ok := severityNames[strings.ToLower(v.DatabaseSeverity)]; ok {
+		return s
+	}
+
+	for _, score := range v.Severity {
+		if score.Type != "CVSS_V3" {
+			continue
+		}
+		if f, err := CVSS3Score(score.Score); err == nil {
+			switch {
+			case f >= 9.0:
+				return Critical
+			case f >= 7.0:
+				return High
+			case f >= 4.0:
+				return Moderate
+			default:
+				return Low
+			}
+		}
+	}
+	return Critical
+}
+
+// Only the database specific severity is read, other fields can be anything
+func (v *Vulnerability) UnmarshalJSON(data []byte) error {
+	type plain Vulnerability
+	raw := struct {
+		*plain
+		DatabaseSpecific map[string]interface{} `json:"database_specific"`
+	}{plain: (*plain)(v)}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	v.DatabaseSeverity, _ = raw.DatabaseSpecific["severity"].(string)
+	return nil
+}
+
+// Check if an affected package covers a Melody package, including
+// packages inside of an affected module (github.com/a/b/sub)
+func (a *Affected) Covers(name string) bool {
+	n := a.Package.Name
+	return n != "" && (name == n || strings.HasPrefix(name, n+"/"))
+}
+
+// Versions affected by the vulnerability.  GIT ranges refer to revisions
+// instead of versions, so they're not included
+func (a *Affected) VersionSet(p version.Parser) (version.Set, error) {
+	set := version.Set{}
+	for _, vStr := range a.Versions {
+		v, err := p.Parse(vStr)
+		if err != nil {
+			return nil, err
+		}
+		exact := version.Bound{Version: v, Inclusive: true}
+		set = set.Union(version.Set{{Lower: exact, Upper: exact}})
+	}
+
+	for _, r := range a.Ranges {
+		if r.Type == "GIT" {
+			continue
+		}
+
+		var lower *version.Bound
+		for _, e := range r.Events {
+			var err error
+			if e.Introduced != "" {
+				lower = &version.Bound{}
+				if e.Introduced != "0" {
+					lower.Version, err = p.Parse(e.Introduced)
+					lower.Inclusive = true
+				}
+			} else if lower != nil && (e.Fixed != "" || e.LastAffected != "") {
+				upper := version.Bound{}
+				if e.Fixed != "" {
+					upper.Version, err = p.Parse(e.Fixed)
+				} else {
+					upper.Version, err = p.Parse(e.LastAffected)
+					upper.Inclusive = true
+				}
+				set, lower = set.Union(version.Set{{Lower: *lower, Upper: upper}}), nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Introduced without a fix affects every later version
+		if lower != nil {
+			set = set.Union(version.Set{{Lower: *lower}})
+		}
+	}
+	return set, nil
+}
+
+// Vulnerabilities loaded from a local copy of an OSV database
+type Database struct {
+	Vulnerabilities []*Vulnerability
+}
+
+// Load a database from a JSON file (one record or a list of them), or
+// from a directory where every JSON file is a record
+func Load(path string) (*Database, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &Database{}
+	if !info.IsDir() {
+		return db, db.loadFile(path)
+	}
+
+	err = filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() || filepath.Ext(p) != ".json" {
+			return err
+		}
+		return db.loadFile(p)
+	})
+
+	sort.Sort(byID(db.Vulnerabilities))
+	return db, err
+}
+
+func (db *Database) loadFile(path string) error {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	vulns := []*Vulnerability{}
+	if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(raw, &vulns)
+	} else {
+		vuln := &Vulnerability{}
+		err = json.Unmarshal(raw, vuln)
+		vulns = append(vulns, vuln)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Invalid OSV record in %s: %s", path, err)
+	}
+	db.Vulnerabilities = append(db.Vulnerabilities, vulns...)
+	return nil
+}
+
+// Vulnerability that affects a package version
+type Match struct {
+	Vulnerability *Vulnerability
+	Affected      version.Set
+}
+
+// Vulnerabilities affecting a version of a package
+func (db *Database) Matches(name string, v version.Version, p version.Parser) ([]*Match, error) {
+	matches := []*Match{}
+	for _, vuln := range db.Vulnerabilities {
+		affected := version.Set{}
+		for i := range vuln.Affected {
+			if !vuln.Affected[i].Covers(name) {
+				continue
+			}
+
+			set, err := vuln.Affected[i].VersionSet(p)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid version in %s: %s", vuln.ID, err)
+			}
+			affected = affected.Union(set)
+		}
+
+		if affected.Contains(v) {
+			matches = append(matches, &Match{vuln, affected})
+		}
+	}
+	return matches, nil
+}
+
+type byID []*Vulnerability
+
+func (s byID) Len() int           { return len(s) }
+func (s byID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byID) Less(i, j int) bool { return s[i].ID < s[j].ID }
@@ -0,0 +1,244 @@
+package osv
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/version"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAffected_VersionSet(t *testing.T) {
+	tests := []struct {
+		affected string
+		in, out  []string
+	}{
+		// Introduced and fixed
+		{`{"ranges": [{"type": "SEMVER", "events": [{"introduced": "1.2.0"}, {"fixed": "1.4.1"}]}]}`,
+			[]string{"1.2.0", "1.3.5", "1.4.0"}, []string{"1.1.9", "1.4.1", "2.0.0"}},
+		// Introduced at the beginning of time
+		{`{"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.9.0"}]}]}`,
+			[]string{"0.0.1", "0.8.9"}, []string{"0.9.0", "1.0.0"}},
+		// Last affected version is included
+		{`{"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.0.0"}, {"last_affected": "2.1.0"}]}]}`,
+			[]string{"2.0.0", "2.1.0"}, []string{"1.9.0", "2.1.1"}},
+		// Unterminated ranges affect every later version
+		{`{"ranges": [{"type": "SEMVER", "events": [{"introduced": "3.0.0"}]}]}`,
+			[]string{"3.0.0", "9.0.0"}, []string{"2.9.9"}},
+		// Several introduced and fixed pairs in one range
+		{`{"ranges": [{"type": "SEMVER", "events": [
+			{"introduced": "1.0.0"}, {"fixed": "1.0.5"}, {"introduced": "1.2.0"}, {"fixed": "1.2.3"}]}]}`,
+			[]string{"1.0.0", "1.0.4", "1.2.2"}, []string{"1.0.5", "1.1.0", "1.2.3"}},
+		// Fixes without an introduced version are ignored
+		{`{"ranges": [{"type": "SEMVER", "events": [{"fixed": "1.0.0"}]}]}`,
+			[]string{}, []string{"0.1.0", "1.0.0"}},
+		// GIT ranges are revisions, only listed versions count
+		{`{"ranges": [{"type": "GIT", "events": [{"introduced": "0"}, {"fixed": "a1b2c3d"}]}], "versions": ["1.0.0", "1.1.0"]}`,
+			[]string{"1.0.0", "1.1.0"}, []string{"1.0.1", "0.9.0"}},
+	}
+
+	for _, test := range tests {
+		affected := &Affected{}
+		if err := json.Unmarshal([]byte(test.affected), affected); err != nil {
+			t.Fatal(err)
+		}
+
+		set, err := affected.VersionSet(flex.VersionParser)
+		if err != nil {
+			t.Errorf("%s: %s", test.affected, err)
+			continue
+		}
+
+		for _, vStr := range append(test.in, test.out...) {
+			v, _ := flex.VersionParser.Parse(vStr)
+			if expected := contains(test.in, vStr); set.Contains(v) != expected {
+				t.Errorf("%s: %s affected is %v, expected %v", set, vStr, !expected, expected)
+			}
+		}
+	}
+
+	// Versions that don't parse are reported
+	affected := &Affected{Ranges: []AffectedRange{{"SEMVER", []Event{{Introduced: "1.0.0"}, {Fixed: "bad"}}}}}
+	if _, err := affected.VersionSet(strictParser{}); err == nil {
+		t.Errorf("expected an error for an invalid version")
+	}
+}
+
+// Parser that rejects anything but numeric versions
+type strictParser struct{}
+
+func (strictParser) Parse(str string) (version.Version, error) {
+	if strings.Trim(str, "0123456789.") != "" {
+		return nil, fmt.Errorf("Invalid version %s", str)
+	}
+	return flex.ParseVersion(str)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAffected_Covers(t *testing.T) {
+	affected := &Affected{}
+	affected.Package.Name = "github.com/a/b"
+
+	tests := map[string]bool{
+		"github.com/a/b":       true,
+		"github.com/a/b/sub":   true,
+		"github.com/a/b/x/y":   true,
+		"github.com/a/bc":      false,
+		"github.com/a":         false,
+		"gopkg.in/a/b":         false,
+		"github.com/a/b-fork/": false,
+	}
+
+	for name, expected := range tests {
+		if affected.Covers(name) != expected {
+			t.Errorf("Covers(%s) = %v, expected %v", name, !expected, expected)
+		}
+	}
+
+	if (&Affected{}).Covers("github.com/a/b") {
+		t.Errorf("Affected packages without a name cover nothing")
+	}
+}
+
+func TestVulnerability_Level(t *testing.T) {
+	tests := []struct {
+		record string
+		level  Severity
+	}{
+		{`{"database_specific": {"severity": "MODERATE"}, "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}]}`, Moderate},
+		{`{"database_specific": {"severity": "low"}}`, Low},
+		{`{"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}]}`, Critical},
+		{`{"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N"}]}`, High},
+		{`{"severity": [{"type": "CVSS_V3", "score": "CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"}]}`, Moderate},
+		{`{"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"}]}`, Low},
+
+		// CVSS v2 vectors, invalid vectors, or no severity at all, are critical
+		{`{"severity": [{"type": "CVSS_V2", "score": "AV:N/AC:L/Au:N/C:N/I:N/P:P"}]}`, Critical},
+		{`{"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:X/AC:L"}]}`, Critical},
+		{`{"database_specific": {"severity": "unheard-of"}}`, Critical},
+		{`{}`, Critical},
+	}
+
+	for _, test := range tests {
+		vuln := &Vulnerability{}
+		if err := json.Unmarshal([]byte(test.record), vuln); err != nil {
+			t.Fatal(err)
+		}
+		if level := vuln.Level(); level != test.level {
+			t.Errorf("%s: level %s, expected %s", test.record, level, test.level)
+		}
+	}
+}
+
+func TestCVSS3Score(t *testing.T) {
+	tests := map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H": 9.9,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N": 7.5,
+		"CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N": 6.1,
+		"CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N": 1.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N": 0,
+	}
+
+	for vector, expected := range tests {
+		if score, err := CVSS3Score(vector); err != nil || score != expected {
+			t.Errorf("CVSS3Score(%s) = %v, %v; expected %v", vector, score, err, expected)
+		}
+	}
+
+	for _, vector := range []string{"AV:N/AC:L/Au:N/C:N/I:N/P:P", "CVSS:3.1/AV:N/AC:L", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:X/C:H/I:H/A:H"} {
+		if _, err := CVSS3Score(vector); err == nil {
+			t.Errorf("expected an error for %s", vector)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for name, expected := range map[string]Severity{"low": Low, "Medium": Moderate, "HIGH": High, "critical": Critical} {
+		if s, err := ParseSeverity(name); err != nil || s != expected {
+			t.Errorf("ParseSeverity(%s) = %s, %v", name, s, err)
+		}
+	}
+
+	if _, err := ParseSeverity("severe"); err == nil {
+		t.Errorf("expected an error for an unknown severity")
+	}
+	if Low >= Moderate || Moderate >= High || High >= Critical {
+		t.Errorf("severities should increase")
+	}
+}
+
+const (
+	recordA = `{"id": "GO-2021-0002", "affected": [{"package": {"name": "github.com/a/b"},
+		"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.2.0"}]}]}]}`
+	recordB = `{"id": "GO-2021-0001", "affected": [{"package": {"name": "github.com/c/d"}, "versions": ["2.0.0"]}]}`
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// Single record or a list of records in one file
+	list := write("list/all.json", "\n ["+recordA+", "+recordB+"]")
+	db, err := Load(list)
+	if err != nil || len(db.Vulnerabilities) != 2 {
+		t.Fatalf("Load(list) = %v, %v", db, err)
+	}
+
+	// Directories are walked for JSON files, sorted by ID
+	write("tree/a/GO-2021-0002.json", recordA)
+	write("tree/GO-2021-0001.json", recordB)
+	write("tree/README.md", "not a record")
+	db, err = Load(filepath.Join(dir, "tree"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []string{}
+	for _, v := range db.Vulnerabilities {
+		ids = append(ids, v.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"GO-2021-0001", "GO-2021-0002"}) {
+		t.Errorf("Load(tree) found %v", ids)
+	}
+
+	// Matches cover packages inside of affected modules
+	v, _ := flex.VersionParser.Parse("1.1.0")
+	matches, err := db.Matches("github.com/a/b/sub", v, flex.VersionParser)
+	if err != nil || len(matches) != 1 || matches[0].Vulnerability.ID != "GO-2021-0002" {
+		t.Errorf("Matches = %v, %v", matches, err)
+	}
+
+	// Broken records and missing paths are errors
+	if _, err := Load(write("broken.json", `{"id": 1}`)); err == nil {
+		t.Errorf("expected an error for an invalid record")
+	}
+	if _, err := Load(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("expected an error for a missing database")
+	}
+}
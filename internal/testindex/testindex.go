@@ -0,0 +1,122 @@
+// Package testindex is a provider for tests, answering from an index of
+// "name version[#revision] [dep range ...]" specs.  Requirements on "head"
+// match the last spec of a package, like the tip of its default branch, and
+// packages under a path share its release ("lib/sub" is released with "lib")
+package testindex
+
+import (
+	"github.com/mdy/melody/provider"
+	"github.com/mdy/melody/resolver"
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/resolver/types"
+	"strings"
+)
+
+// Package version, optionally at a revision of its repository
+type Spec struct {
+	flex.Specification
+	Rev string
+}
+
+func (s *Spec) Revision() string {
+	return s.Rev
+}
+
+func (s *Spec) ReleaseSpec() provider.ReleaseSpec {
+	repo := strings.SplitN(s.NameStr, "/", 2)[0]
+	return &Release{*flex.NewSpec("repo://"+repo, s.VersStr)}
+}
+
+// Repository release of a package
+type Release struct {
+	flex.Specification
+}
+
+func (r *Release) ExternalName() string {
+	return strings.TrimPrefix(r.NameStr, "repo://")
+}
+
+func (r *Release) InstallPath() string {
+	return r.ExternalName()
+}
+
+type Provider struct {
+	resolver.BaseProvider
+	Specs []types.Specification
+}
+
+func New(index ...string) *Provider {
+	p := &Provider{}
+	for _, line := range index {
+		fields := strings.Fields(line)
+		spec := &Spec{}
+		if i := strings.Index(fields[1], "#"); i >= 0 {
+			spec.Specification, spec.Rev = *flex.NewSpec(fields[0], fields[1][:i]), fields[1][i+1:]
+		} else {
+			spec.Specification = *flex.NewSpec(fields[0], fields[1])
+		}
+		for i := 2; i+1 < len(fields); i += 2 {
+			spec.Dependencies = append(spec.Dependencies, flex.NewDependency(fields[i], fields[i+1]))
+		}
+		p.Specs = append(p.Specs, spec)
+	}
+	return p
+}
+
+// First spec of a package at a version, or nil
+func (p *Provider) Spec(name, version string) types.Specification {
+	for _, spec := range p.Specs {
+		if spec.Name() == name && spec.Version() == version {
+			return spec
+		}
+	}
+	return nil
+}
+
+// Spec of a package at a revision, or nil
+func (p *Provider) Revision(name, revision string) *Spec {
+	for _, spec := range p.Specs {
+		if s, ok := spec.(*Spec); ok && s.NameStr == name && s.Rev == revision {
+			return s
+		}
+	}
+	return nil
+}
+
+func (p *Provider) NewRequirement(name, r string) types.Requirement {
+	return flex.NewDependency(name, r)
+}
+
+func (p *Provider) SearchFor(req types.Requirement) []types.Specification {
+	specs := []types.Specification{}
+	for _, spec := range p.Specs {
+		if p.IsRequirementSatisfiedBy(req, nil, spec) {
+			specs = append(specs, spec)
+		}
+	}
+	if len(specs) > 0 && isHead(req) {
+		return specs[len(specs)-1:]
+	}
+	return specs
+}
+
+func (p *Provider) DependenciesFor(spec types.Specification) types.Requirements {
+	return spec.Requirements()
+}
+
+func (p *Provider) IsRequirementSatisfiedBy(req types.Requirement, _ *resolver.Graph, spec types.Specification) bool {
+	if isHead(req) {
+		return req.Name() == spec.Name()
+	}
+	ok, err := req.SatisfiedBy(spec)
+	return err == nil && ok
+}
+
+func (p *Provider) InstallToDir(_ string, _ []types.Specification) error {
+	return nil
+}
+
+func isHead(req types.Requirement) bool {
+	d, ok := req.(*flex.Dependency)
+	return ok && d.Constraint() == "head"
+}
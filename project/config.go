@@ -2,12 +2,16 @@ package project
 
 import (
 	"github.com/BurntSushi/toml"
+	"github.com/mdy/melody/internal/license"
 	"github.com/mdy/melody/provider"
 	"github.com/mdy/melody/provider/melody"
 	"github.com/mdy/melody/resolver"
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/version"
 
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -27,15 +31,31 @@ type Project struct {
 	// Locked dependencies graph
 	Locked *resolver.Graph
 
+	// Resolver output (defaults to STDOUT)
+	UI resolver.UI
+
+	// Path to record resolution sessions for replay
+	RecordPath string
+
+	// Hooks following every resolution (optional)
+	Events resolver.Events
+
+	// Resolve without writing Melody.lock or ./vendor
+	DryRun bool
+
 	// Root directory
 	root string
 }
 
 type Config struct {
-	Name         string            `toml:"name"`
-	Version      string            `toml:"version"`
-	Authors      []string          `toml:"authors"`
-	Dependencies map[string]string `toml:"dependencies,omitempty"`
+	Name         string            `toml:"name" json:"name"`
+	Version      string            `toml:"version" json:"version"`
+	Authors      []string          `toml:"authors" json:"authors"`
+	Resolution   string            `toml:"resolution,omitempty" json:"resolution,omitempty"`
+	Dependencies map[string]string `toml:"dependencies,omitempty" json:"dependencies,omitempty"`
+	Replace      map[string]string `toml:"replace,omitempty" json:"replace,omitempty"`
+	Exclude      map[string]string `toml:"exclude,omitempty" json:"exclude,omitempty"`
+	Policy       license.Policy    `toml:"policy,omitempty" json:"policy"`
 }
 
 type Locked struct {
@@ -64,7 +84,11 @@ func Load(root string) (*Project, error) {
 
 // Initialize Specification provider for this project
 func (p *Project) Provider() provider.Provider {
-	return melody.New(p.Locked)
+	source := melody.New(p.Locked)
+	for name, versionRange := range p.Config.Exclude {
+		source.Exclude(name, versionRange)
+	}
+	return source
 }
 
 func (p *Project) parseConfig() error {
@@ -80,6 +104,16 @@ func (p *Project) parseConfig() error {
 
 	p.Config = tomlConfig.Project
 	p.Config.Dependencies = tomlConfig.Dependencies
+	p.Config.Replace = tomlConfig.Replace
+	p.Config.Exclude = tomlConfig.Exclude
+	p.Config.Policy = tomlConfig.Policy
+
+	// Excluded ranges are checked on every search, so they must parse
+	for name, r := range p.Config.Exclude {
+		if _, err := version.ParseRange(r, flex.VersionParser); err != nil {
+			return fmt.Errorf("Invalid [exclude] range \"%s\" for %s: %s", r, name, err)
+		}
+	}
 	return nil
 }
 
@@ -117,6 +151,9 @@ func loadTOMLFile(path string, data interface{}) error {
 type tomlRootConfig struct {
 	Project      Config
 	Dependencies map[string]string
+	Replace      map[string]string
+	Exclude      map[string]string
+	Policy       license.Policy
 	Overrides    []tomlOverrideConfig
 
 	// DEPRECATED: Use Project
@@ -0,0 +1,27 @@
+package project
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfig_Exclude(t *testing.T) {
+	tests := []struct {
+		exclude string
+		err     string
+	}{
+		{`"github.com/x/lib" = ">= 1.0, < 1.2"`, ""},
+		{`"github.com/x/lib" = "1.1.0 || 1.3.x"`, ""},
+		{`"github.com/x/lib" = "~> nope"`, `Invalid [exclude] range "~> nope" for github.com/x/lib`},
+	}
+
+	for _, test := range tests {
+		p := &Project{configData: []byte("[exclude]\n" + test.exclude + "\n")}
+		err := p.parseConfig()
+		if test.err == "" && err != nil {
+			t.Errorf("%s: unexpected error %s", test.exclude, err)
+		} else if test.err != "" && (err == nil || !strings.HasPrefix(err.Error(), test.err)) {
+			t.Errorf("%s: expected error %q, got %v", test.exclude, test.err, err)
+		}
+	}
+}
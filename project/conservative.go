@@ -0,0 +1,137 @@
+package project
+
+import (
+	"fmt"
+	"github.com/mdy/melody/provider"
+	"github.com/mdy/melody/resolver"
+	log "github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+)
+
+// Package that had to be unlocked in addition to the requested ones
+type Unlocked struct {
+	Name       string `json:"name"`
+	OldVersion string `json:"oldVersion"`
+	NewVersion string `json:"newVersion,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// Update named packages while everything else stays locked.  If that can't be
+// resolved, conflicting packages and then their dependents are unlocked one
+// step at a time, so that the lockfile changes as little as possible
+func (p *Project) UpdateConservatively(src provider.Provider, names []string) ([]*Unlocked, error) {
+	res, recorder, err := p.recordingResolver(src)
+	if err != nil {
+		return nil, err
+	}
+
+	rDeps := p.requested(src)
+	unlocked, reasons := map[string]bool{}, map[string]string{}
+	for _, name := range names {
+		unlocked[name] = true
+	}
+
+	for {
+		base := p.Locked.Dup()
+		for name := range unlocked {
+			base.RemoveNamedVertex(name)
+		}
+
+		useBase(src, base)
+		out, err := res.Resolve(rDeps, base)
+		vErr, isConflict := err.(*resolver.VersionConflictError)
+		if err != nil && !isConflict {
+			return nil, err
+		} else if err == nil {
+			if err := p.saveSession(recorder, res, rDeps, base); err != nil {
+				return nil, err
+			}
+			moved := p.movedPackages(out, reasons)
+			return moved, p.install(src, out)
+		}
+
+		// Unlock conflicting packages, or their dependents as a last resort
+		expanded := false
+		for _, report := range vErr.Report() {
+			if !unlocked[report.Name] && p.Locked.PayloadFor(report.Name) != nil {
+				unlocked[report.Name], expanded = true, true
+				reasons[report.Name] = conflictReason(report)
+			}
+		}
+
+		if !expanded {
+			for _, report := range vErr.Report() {
+				for _, dep := range p.Locked.DependentsOf(report.Name) {
+					if !unlocked[dep] {
+						unlocked[dep], expanded = true, true
+						reasons[dep] = dependentReason(report.Name)
+					}
+				}
+			}
+		}
+
+		if !expanded {
+			res.Suggest(rDeps, base, vErr)
+			if err := p.saveSession(recorder, res, rDeps, base); err != nil {
+				return nil, err
+			}
+			return nil, vErr
+		}
+		log.Info("Unlocked packages: ", unlocked)
+	}
+}
+
+// Extra packages that were unlocked and resolved to a different version
+func (p *Project) movedPackages(out *resolver.Graph, reasons map[string]string) []*Unlocked {
+	moved := []*Unlocked{}
+	for name, reason := range reasons {
+		oldSpec, newSpec := p.Locked.PayloadFor(name), out.PayloadFor(name)
+		if strings.HasPrefix(name, "repo://") || oldSpec == nil {
+			continue
+		}
+
+		item := &Unlocked{Name: name, OldVersion: oldSpec.Version(), Reason: reason}
+		if newSpec != nil {
+			item.NewVersion = newSpec.Version()
+		}
+
+		if item.OldVersion != item.NewVersion {
+			moved = append(moved, item)
+		}
+	}
+
+	sort.Sort(unlockedSort(moved))
+	return moved
+}
+
+// Short description of why a locked package is in conflict
+func conflictReason(report *resolver.ConflictReport) string {
+	for _, r := range report.Requirements {
+		if l := len(r.RequiredBy); l > 0 {
+			parent := r.RequiredBy[l-1]
+			return fmt.Sprintf("%s %s requires %s", externalName(parent.Name), parent.Version, r.Constraint)
+		}
+	}
+	return "conflicts with Melody.toml"
+}
+
+// Short description of why a dependent is unlocked
+func dependentReason(name string) string {
+	if strings.HasPrefix(name, "repo://") {
+		return "shares repository " + externalName(name)
+	}
+	return "depends on " + name
+}
+
+// Release names are only used internally
+func externalName(name string) string {
+	return strings.TrimPrefix(name, "repo://")
+}
+
+// Sorting unlocked packages by name
+type unlockedSort []*Unlocked
+
+func (s unlockedSort) Len() int           { return len(s) }
+func (s unlockedSort) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s unlockedSort) Less(i, j int) bool { return s[i].Name < s[j].Name }
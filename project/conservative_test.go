@@ -0,0 +1,179 @@
+package project
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/mdy/melody/internal/testindex"
+	"github.com/mdy/melody/resolver"
+	"github.com/mdy/melody/resolver/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Graph from Melody.lock contents, with specs looked up in the index
+type indexDecoder struct {
+	raw      string
+	provider *testindex.Provider
+}
+
+func (d *indexDecoder) Decode(v interface{}) error {
+	_, err := toml.Decode(d.raw, v)
+	return err
+}
+
+func (d *indexDecoder) NewSpec(i *resolver.GraphItem) (types.Specification, error) {
+	if spec := d.provider.Spec(i.Name, i.Version); spec != nil {
+		return spec, nil
+	}
+	return nil, fmt.Errorf("%s %s is not in the index", i.Name, i.Version)
+}
+
+func indexProject(t *testing.T, src *testindex.Provider, deps map[string]string, lock string) *Project {
+	locked, err := resolver.DecodeGraph(&indexDecoder{lock, src})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ui := resolver.NewWriterUI(ioutil.Discard)
+	return &Project{Config: Config{Dependencies: deps}, Locked: locked, UI: ui, DryRun: true}
+}
+
+const conservativeLock = `
+[project]
+dependencies = ["app 1.0.0", "other 1.0.0"]
+
+[[packages]]
+name = "app"
+version = "1.0.0"
+dependencies = ["lib 1.0.0"]
+
+[[packages]]
+name = "lib"
+version = "1.0.0"
+
+[[packages]]
+name = "other"
+version = "1.0.0"
+`
+
+func TestProject_UpdateConservatively(t *testing.T) {
+	src := testindex.New(
+		"app 1.0.0 lib <2.0",
+		"app 2.0.0 lib >=2.0",
+		"lib 1.0.0",
+		"lib 1.1.0",
+		"lib 2.0.0",
+		"other 1.0.0",
+		"other 1.1.0",
+	)
+
+	tests := []struct {
+		deps     map[string]string
+		names    []string
+		moved    string
+		resolved string
+	}{
+		// Nothing else moves if the named package can be updated alone
+		{
+			deps:     map[string]string{"app": ">= 1.0", "other": ">= 1.0"},
+			names:    []string{"lib"},
+			moved:    "",
+			resolved: "app 1.0.0, lib 1.1.0, other 1.0.0",
+		},
+		// Locked packages in conflict are unlocked first
+		{
+			deps:     map[string]string{"app": ">= 2.0", "other": ">= 1.0"},
+			names:    []string{"app"},
+			moved:    "lib 1.0.0 => 2.0.0 (app 2.0.0 requires >=2.0)",
+			resolved: "app 2.0.0, lib 2.0.0, other 1.0.0",
+		},
+		// Then their dependents, as a last resort
+		{
+			deps:     map[string]string{"app": ">= 1.0", "lib": ">= 2.0", "other": ">= 1.0"},
+			names:    []string{"lib"},
+			moved:    "app 1.0.0 => 2.0.0 (depends on lib)",
+			resolved: "app 2.0.0, lib 2.0.0, other 1.0.0",
+		},
+	}
+
+	for _, test := range tests {
+		p := indexProject(t, src, test.deps, conservativeLock)
+		moved, err := p.UpdateConservatively(src, test.names)
+		if err != nil {
+			t.Errorf("%v: %s", test.names, err)
+			continue
+		}
+
+		descriptions := []string{}
+		for _, m := range moved {
+			descriptions = append(descriptions, fmt.Sprintf("%s %s => %s (%s)", m.Name, m.OldVersion, m.NewVersion, m.Reason))
+		}
+		if out := strings.Join(descriptions, ", "); out != test.moved {
+			t.Errorf("%v moved %q, expected %q", test.names, out, test.moved)
+		}
+
+		resolved := []string{}
+		for _, spec := range p.Locked.Specifications() {
+			resolved = append(resolved, spec.Name()+" "+spec.Version())
+		}
+		if out := strings.Join(resolved, ", "); out != test.resolved {
+			t.Errorf("%v resolved %q, expected %q", test.names, out, test.resolved)
+		}
+	}
+}
+
+func TestProject_UpdateConservativelyConflict(t *testing.T) {
+	src := testindex.New("app 1.0.0 lib <2.0", "lib 1.0.0", "other 1.0.0")
+	deps := map[string]string{"app": ">= 1.0", "lib": ">= 2.0", "other": ">= 1.0"}
+	p := indexProject(t, src, deps, conservativeLock)
+
+	// Unlocking can't help when no version is published
+	_, err := p.UpdateConservatively(src, []string{"lib"})
+	if _, ok := err.(*resolver.VersionConflictError); !ok {
+		t.Errorf("expected a version conflict, got %v", err)
+	}
+}
+
+func TestProject_UpdateConservativelyRecord(t *testing.T) {
+	src := testindex.New("app 1.0.0 lib <2.0", "lib 1.0.0", "lib 1.1.0", "other 1.0.0")
+	deps := map[string]string{"app": ">= 1.0", "other": ">= 1.0"}
+	p := indexProject(t, src, deps, conservativeLock)
+
+	dir, err := ioutil.TempDir("", "melody")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p.RecordPath = filepath.Join(dir, "session.json")
+
+	if _, err := p.UpdateConservatively(src, []string{"lib"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The session replays the last resolution, with lib unlocked
+	file, err := os.Open(p.RecordPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	session, err := resolver.DecodeSession(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base := session.BaseGraph(); base.PayloadFor("lib") != nil || base.PayloadFor("app") == nil {
+		t.Errorf("recorded base %s should only lock app and other", base)
+	}
+
+	res := resolver.NewResolver(session.Provider(), resolver.NewWriterUI(ioutil.Discard))
+	out, err := res.Resolve(session.RequestedRequirements(), session.BaseGraph())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := out.PayloadFor("lib").Version(); v != "1.1.0" {
+		t.Errorf("replay resolved lib %s, expected 1.1.0", v)
+	}
+}
@@ -0,0 +1,124 @@
+package project
+
+import (
+	"fmt"
+	"github.com/mdy/melody/internal/license"
+	"github.com/mdy/melody/provider"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// License files of a release installed in ./vendor
+type ReleaseLicenses struct {
+	Name      string          `json:"name"`
+	Version   string          `json:"version"`
+	Installed bool            `json:"installed"`
+	Licenses  []string        `json:"licenses"`
+	Files     []*license.File `json:"files"`
+}
+
+// Releases that violate the [policy] of Melody.toml
+type LicensePolicyError struct {
+	Problems []string
+}
+
+func (e *LicensePolicyError) Error() string {
+	return "License policy violations:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Directory where releases are installed, in the working directory
+func vendorDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vendor"), nil
+}
+
+// Scan every locked release in ./vendor for license files
+func (p *Project) Licenses() ([]*ReleaseLicenses, error) {
+	vendor, err := vendorDir()
+	if err != nil {
+		return nil, err
+	}
+	return p.licensesIn(vendor)
+}
+
+// Scan every locked release for license files, in the first directory
+// where it is installed
+func (p *Project) licensesIn(dirs ...string) ([]*ReleaseLicenses, error) {
+	out, seen := []*ReleaseLicenses{}, map[string]bool{}
+	for _, spec := range p.Locked.Specifications() {
+		release, ok := spec.(provider.ReleaseSpec)
+		if v, isVer := spec.(provider.VersionSpec); isVer {
+			release, ok = v.ReleaseSpec(), v.ReleaseSpec() != nil
+		}
+		if !ok || seen[release.Name()] {
+			continue
+		}
+		seen[release.Name()] = true
+
+		item := &ReleaseLicenses{Name: release.ExternalName(), Version: release.Version()}
+		var files []*license.File
+		err := os.ErrNotExist
+		for _, dir := range dirs {
+			if files, err = license.Scan(filepath.Join(dir, release.InstallPath())); !os.IsNotExist(err) {
+				break
+			}
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		item.Installed = err == nil
+		item.Files = files
+		item.Licenses = license.IDs(files)
+		out = append(out, item)
+	}
+
+	sort.Sort(releaseLicensesSort(out))
+	return out, nil
+}
+
+// Check installed releases against the license policy
+func (p *Project) CheckLicenses() error {
+	vendor, err := vendorDir()
+	if err != nil {
+		return err
+	}
+	return p.checkLicensesIn(vendor)
+}
+
+// Check releases installed in directories against the license policy
+func (p *Project) checkLicensesIn(dirs ...string) error {
+	if p.Config.Policy.IsEmpty() {
+		return nil
+	}
+
+	releases, err := p.licensesIn(dirs...)
+	if err != nil {
+		return err
+	}
+
+	problems := []string{}
+	for _, r := range releases {
+		if !r.Installed {
+			continue
+		} else if err := p.Config.Policy.Check(r.Licenses); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s: %s", r.Name, r.Version, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &LicensePolicyError{Problems: problems}
+	}
+	return nil
+}
+
+type releaseLicensesSort []*ReleaseLicenses
+
+func (s releaseLicensesSort) Len() int           { return len(s) }
+func (s releaseLicensesSort) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s releaseLicensesSort) Less(i, j int) bool { return s[i].Name < s[j].Name }
@@ -32,6 +32,11 @@ func (p *Project) LoadLockfile(path string) error {
 	return err
 }
 
+// Decode Melody.lock contents, like an older revision from git
+func DecodeLockfile(raw []byte) (*resolver.Graph, error) {
+	return resolver.DecodeGraph(&LockEncoderDecoder{raw: raw})
+}
+
 func (p *Project) saveLockfile() error {
 	path := filepath.Join(p.root, lockedFile)
 	encoder := &LockEncoderDecoder{path: path, config: &p.Config}
@@ -41,11 +46,15 @@ func (p *Project) saveLockfile() error {
 // Graph encoder/decoder
 type LockEncoderDecoder struct {
 	path           string  // Lockfile path
+	raw            []byte  // Lockfile contents, instead of reading path
 	config         *Config // Project config
 	melody.Builder         // provides NewSpec(...)
 }
 
 func (l *LockEncoderDecoder) Decode(v interface{}) error {
+	if l.raw != nil {
+		return toml.Unmarshal(l.raw, v)
+	}
 	return loadTOMLFile(l.path, v)
 }
 
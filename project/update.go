@@ -1,10 +1,12 @@
 package project
 
 import (
+	"fmt"
 	"github.com/mdy/melody/provider"
 	"github.com/mdy/melody/resolver"
 	"github.com/mdy/melody/resolver/types"
 	log "github.com/sirupsen/logrus"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 )
@@ -15,16 +17,108 @@ import (
 // 2. Lock nothing, update everything (update)
 // 3. Lock some packages (update <pkg>)
 func (p *Project) Resolve(src provider.Provider, base *resolver.Graph) (*resolver.Graph, error) {
-	// Convert Project.Config to Requested
-	rDeps := []types.Requirement{}
-	for name, r := range p.Config.Dependencies {
-		rDeps = append(rDeps, src.NewRequirement(name, r))
+	rDeps := p.requested(src)
+	res, recorder, err := p.recordingResolver(src)
+	if err != nil {
+		return nil, err
 	}
 
 	// Resolve dependencies
 	log.Info("Dependencies", rDeps)
-	res := resolver.NewResolver(src, resolver.NewStdoutUI())
-	return res.Resolve(rDeps, base)
+	useBase(src, base)
+	out, err := res.Resolve(rDeps, base)
+
+	// Look for actionable suggestions to fix conflicts
+	if vErr, ok := err.(*resolver.VersionConflictError); ok {
+		res.Suggest(rDeps, base, vErr)
+	}
+
+	if sErr := p.saveSession(recorder, res, rDeps, base); sErr != nil {
+		return out, sErr
+	}
+	return out, err
+}
+
+// Resolver that captures all provider answers to replay a session, when
+// Project.RecordPath is set (the recorder is nil otherwise)
+func (p *Project) recordingResolver(src provider.Provider) (*resolver.Resolver, *resolver.Recorder, error) {
+	if p.RecordPath == "" {
+		res, err := p.resolver(src, src)
+		return res, nil, err
+	}
+
+	recorder := resolver.NewRecorder(src)
+	res, err := p.resolver(src, recorder)
+	return res, recorder, err
+}
+
+// Save the recorded session of a resolution to Project.RecordPath
+func (p *Project) saveSession(recorder *resolver.Recorder, res *resolver.Resolver, rDeps types.Requirements, base *resolver.Graph) error {
+	if recorder == nil {
+		return nil
+	}
+
+	session := recorder.Session(rDeps, base)
+	session.Strategy = res.Strategy.String()
+	session.SetOverrides(res.Overrides)
+	return writeSession(p.RecordPath, session)
+}
+
+func writeSession(path string, session *resolver.Session) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return session.Encode(file)
+}
+
+// Resolver configured from project settings
+func (p *Project) resolver(src provider.Provider, sp resolver.SpecificationProvider) (*resolver.Resolver, error) {
+	// Newest versions or minimal version selection
+	strategy, err := resolver.ParseStrategy(p.Config.Resolution)
+	if err != nil {
+		return nil, err
+	}
+
+	res := resolver.NewResolver(sp, p.ui())
+	res.Strategy = strategy
+	res.Overrides = p.overrides(src)
+	res.Events = p.Events
+	return res, nil
+}
+
+// Let the provider know which packages stay locked in a resolution
+func useBase(src provider.Provider, base *resolver.Graph) {
+	if b, ok := src.(provider.BaseGraphProvider); ok {
+		b.SetBase(base)
+	}
+}
+
+// Resolver UI or STDOUT by default
+func (p *Project) ui() resolver.UI {
+	if p.UI == nil {
+		return resolver.NewStdoutUI()
+	}
+	return p.UI
+}
+
+// Convert Project.Config to Requested
+func (p *Project) requested(src provider.Provider) types.Requirements {
+	rDeps := types.Requirements{}
+	for name, r := range p.Config.Dependencies {
+		rDeps = append(rDeps, src.NewRequirement(name, r))
+	}
+	return rDeps
+}
+
+// Convert Project.Config [replace] table to forced requirements
+func (p *Project) overrides(src provider.Provider) resolver.Overrides {
+	reqs := types.Requirements{}
+	for name, r := range p.Config.Replace {
+		reqs = append(reqs, src.NewRequirement(name, r))
+	}
+	return resolver.NewOverrides(reqs)
 }
 
 // Resolve project specifications and install them in ./vendor
@@ -34,19 +128,128 @@ func (p *Project) UpdateWithBase(src provider.Provider, base *resolver.Graph) er
 	if outErr != nil {
 		return outErr
 	}
+	return p.install(src, out)
+}
+
+// Lock resolved graph and install its packages in ./vendor, or only
+// replace Project.Locked in memory for a dry run
+func (p *Project) install(src provider.Provider, out *resolver.Graph) error {
+	// Strict check to never lock an incomplete graph
+	overrides := p.overrides(src)
+	if err := out.ValidateWithOverrides(p.requested(src), src, overrides); err != nil {
+		return err
+	}
+
+	// Warn about every requirement that a forced version ignores (on STDERR,
+	// so that structured output stays valid)
+	for _, o := range out.Overridden(overrides, src) {
+		fmt.Fprintf(os.Stderr, "♫ Warning: %s\n", o)
+	}
+
+	// Yanked versions are only kept when they were locked
+	for _, spec := range out.Specifications() {
+		if y, ok := spec.(provider.YankedSpec); ok && y.IsYanked() {
+			fmt.Fprintf(os.Stderr, "♫ Warning: %s %s is yanked, but pinned in Melody.lock\n", spec.Name(), spec.Version())
+		}
+	}
 
 	// Save state
+	previous := p.Locked
 	p.Locked = out
+	if p.DryRun {
+		return nil
+	}
+
+	// Install packages to destination
+	if err := p.installStaged(src, previous); err != nil {
+		p.Locked = previous
+		return err
+	}
+
 	log.Info("Saving lockfile: ", p.Save())
+	return nil
+}
 
-	// Start package installation
-	dir, err := os.Getwd()
+// Install releases that changed since the previous lock into a stage next
+// to ./vendor, and only move them into ./vendor once every release follows
+// the license policy.  Otherwise ./vendor is left as it was, matching the
+// Melody.lock that isn't saved
+func (p *Project) installStaged(src provider.Provider, previous *resolver.Graph) error {
+	vendor, err := vendorDir()
 	if err != nil {
 		return err
 	}
 
-	// Install packages to destination
-	target := filepath.Join(dir, "vendor")
-	err = src.InstallToDir(target, p.Locked.Specifications())
-	return err
+	// Stages left behind by an interrupted install
+	parent := filepath.Dir(vendor)
+	stale, _ := filepath.Glob(filepath.Join(parent, ".melody-vendor-*"))
+	for _, dir := range stale {
+		os.RemoveAll(dir)
+	}
+
+	stage, err := ioutil.TempDir(parent, ".melody-vendor-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stage)
+
+	changed := changedReleases(vendor, previous, p.Locked)
+	specs := []types.Specification{}
+	for _, spec := range p.Locked.Specifications() {
+		if r, ok := spec.(provider.ReleaseSpec); !ok || changed[r.Name()] {
+			specs = append(specs, spec)
+		}
+	}
+	if err := src.InstallToDir(stage, specs); err != nil {
+		return err
+	}
+
+	// Changed releases are checked in the stage, the others in ./vendor
+	if err := p.checkLicensesIn(stage, vendor); err != nil {
+		return err
+	}
+
+	for _, spec := range p.Locked.Specifications() {
+		if r, ok := spec.(provider.ReleaseSpec); ok && changed[r.Name()] {
+			if err := moveRelease(filepath.Join(stage, r.InstallPath()), filepath.Join(vendor, r.InstallPath())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Names of releases that are new in the graph, at another version, or that
+// are missing from ./vendor
+func changedReleases(vendor string, previous, out *resolver.Graph) map[string]bool {
+	changed := map[string]bool{}
+	for _, spec := range out.Specifications() {
+		r, ok := spec.(provider.ReleaseSpec)
+		if !ok {
+			continue
+		}
+
+		var old types.Specification
+		if previous != nil {
+			old = previous.PayloadFor(r.Name())
+		}
+		if _, err := os.Stat(filepath.Join(vendor, r.InstallPath())); err != nil || old == nil || old.Version() != r.Version() {
+			changed[r.Name()] = true
+		}
+	}
+	return changed
+}
+
+// Replace an installed release with the staged one
+func moveRelease(staged, target string) error {
+	if _, err := os.Stat(staged); os.IsNotExist(err) {
+		return nil // Nothing was installed
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.Rename(staged, target)
 }
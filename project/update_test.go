@@ -0,0 +1,136 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mdy/melody/internal/license"
+	"github.com/mdy/melody/internal/testindex"
+	"github.com/mdy/melody/resolver"
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/resolver/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	mitText = "Permission is hereby granted, free of charge, to any person obtaining a copy"
+	gplText = "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007"
+)
+
+// Release installed with a LICENSE file
+type licensedSpec struct {
+	*flex.Specification
+	text string
+}
+
+func (s *licensedSpec) ExternalName() string { return s.NameStr }
+func (s *licensedSpec) InstallPath() string  { return s.NameStr }
+
+// Index provider that installs the LICENSE file of every release
+type licensedProvider struct {
+	*testindex.Provider
+	installed []string
+}
+
+func (p *licensedProvider) InstallToDir(dir string, specs []types.Specification) error {
+	for _, spec := range specs {
+		p.installed = append(p.installed, spec.Name())
+		target := filepath.Join(dir, spec.Name())
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return err
+		}
+		text := []byte(spec.(*licensedSpec).text)
+		if err := ioutil.WriteFile(filepath.Join(target, "LICENSE"), text, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestProject_InstallLicensePolicy(t *testing.T) {
+	src := &licensedProvider{Provider: testindex.New()}
+	src.Specs = []types.Specification{
+		&licensedSpec{flex.NewSpec("lib", "1.0.0"), mitText},
+		&licensedSpec{flex.NewSpec("lib", "2.0.0"), gplText},
+		&licensedSpec{flex.NewSpec("other", "1.0.0"), mitText},
+	}
+
+	dir, err := ioutil.TempDir("", "melody")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Releases are installed in ./vendor of the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Install lib 1.0.0 before updating to the GPL version
+	deps := map[string]string{"lib": "1.0.0", "other": "1.0.0"}
+	p := &Project{root: dir, Locked: resolver.NewGraph(), UI: resolver.NewWriterUI(ioutil.Discard)}
+	p.Config = Config{Dependencies: deps, Policy: license.Policy{Deny: []string{"copyleft"}}}
+	if err := p.UpdateWithBase(src, resolver.NewGraph()); err != nil {
+		t.Fatal(err)
+	}
+	lock, err := ioutil.ReadFile(filepath.Join(dir, lockedFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stage left behind by an interrupted install
+	if err := os.MkdirAll(filepath.Join(dir, ".melody-vendor-1", "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	src.installed = nil
+	p.Config.Dependencies = map[string]string{"lib": ">= 2.0", "other": "1.0.0"}
+	err = p.UpdateWithBase(src, resolver.NewGraph())
+	if _, ok := err.(*LicensePolicyError); !ok {
+		t.Fatalf("expected a license policy error, got %v", err)
+	}
+
+	// Only the changed release was installed, and neither ./vendor nor
+	// Melody.lock change.  Stages are removed, including the stale one
+	if fmt.Sprint(src.installed) != "[lib]" {
+		t.Errorf("installed %v, expected [lib]", src.installed)
+	}
+	if v := p.Locked.PayloadFor("lib").Version(); v != "1.0.0" {
+		t.Errorf("locked lib %s, expected 1.0.0", v)
+	}
+	if text, _ := ioutil.ReadFile(filepath.Join(dir, "vendor", "lib", "LICENSE")); string(text) != mitText {
+		t.Errorf("vendor/lib was replaced with %q", text)
+	}
+	if after, _ := ioutil.ReadFile(filepath.Join(dir, lockedFile)); string(after) != string(lock) {
+		t.Errorf("Melody.lock was replaced with:\n%s", after)
+	}
+	if entries, _ := ioutil.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("expected only vendor and Melody.lock, found %d entries", len(entries))
+	}
+}
+
+func TestProject_ResolveEvents(t *testing.T) {
+	src := testindex.New("app 1.0.0 lib <2.0", "lib 1.0.0", "other 1.0.0")
+	deps := map[string]string{"app": ">= 1.0", "other": ">= 1.0"}
+	p := indexProject(t, src, deps, conservativeLock)
+
+	out := &bytes.Buffer{}
+	p.Events = resolver.NewJSONEvents(out)
+	if _, err := p.Resolve(src, p.Locked); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every resolution of the project reports to its hooks
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, `{"event":"complete","success":true`) {
+		t.Errorf("expected a complete event last, got %s", last)
+	}
+}
@@ -21,28 +21,91 @@ import (
 
 const maxParallelInstalls = 5
 
+// Requirement prefix to track a branch (branch:release-2.x)
+const branchPrefix = "branch:"
+
 type Melody struct {
 	resolver.BaseProvider
 	sessionID string
 	client    *http.Client
 	base      *resolver.Graph
 	cache     *Cache
+	branches  map[string][]types.Specification
+	excluded  map[string]types.Requirements
+	fetches   int
+
+	// Registry doesn't know about yanked versions
+	withoutYanked bool
 }
 
 func New(base *resolver.Graph) *Melody {
 	source := &Melody{base: base, sessionID: uuid.NewV4().String()}
+	source.branches = map[string][]types.Specification{}
+	source.excluded = map[string]types.Requirements{}
 	source.cache = NewCache(source.fetchAvailableSpecs)
 	source.client = &http.Client{Transport: source}
 	return source
 }
 
-// Look for specifications that match passed-in dependency (name + requirement)
+// Graph that is being resolved from.  Only its packages stay at their locked
+// revision, so a branch moves to its tip once the package is unlocked
+func (p *Melody) SetBase(base *resolver.Graph) {
+	p.base = base
+	p.branches = map[string][]types.Specification{}
+}
+
+// Ban versions of a package matching a range, unless they're already locked
+func (p *Melody) Exclude(name, versionRange string) {
+	p.excluded[name] = append(p.excluded[name], p.NewRequirement(name, versionRange))
+}
+
+// Look for specifications that match passed-in dependency (name + requirement),
+// without excluded or yanked versions that are not locked
 func (p *Melody) SearchFor(req types.Requirement) []types.Specification {
+	specs := []types.Specification{}
+	for _, spec := range p.search(req) {
+		if p.isLocked(spec) || !p.isBanned(spec) {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// Check if the base graph locks this exact version
+func (p *Melody) isLocked(spec types.Specification) bool {
+	if p.base == nil {
+		return false
+	}
+	locked := p.base.PayloadFor(spec.Name())
+	return locked != nil && resolver.SpecEqual(locked, spec)
+}
+
+// Check if the version is yanked or excluded by the project
+func (p *Melody) isBanned(spec types.Specification) bool {
+	if mSpec, ok := spec.(*melodySpec); ok && mSpec.Yanked {
+		return true
+	}
+	for _, req := range p.excluded[spec.Name()] {
+		if p.IsRequirementSatisfiedBy(req, nil, spec) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Melody) search(req types.Requirement) []types.Specification {
 	// Looking for a melodyRelease gets you that melodyRelease
 	if mSpec, isRelease := req.(*melodyRelease); isRelease {
 		return []types.Specification{mSpec}
 	}
 
+	// Branches resolve to a single revision, not to any cached version
+	if dep, ok := req.(*melodyRequirement); ok {
+		if branch, isBranch := dep.Branch(); isBranch {
+			return p.searchBranch(dep.Name(), branch)
+		}
+	}
+
 	// Let's check the cache for matches first
 	availableSpecs, err := p.cache.Fetch(req.Name())
 	if err != nil {
@@ -91,6 +154,69 @@ func (p *Melody) SearchFor(req types.Requirement) []types.Specification {
 	return specs
 }
 
+// Specs at the revision of a branch locked in the base graph, or at its tip
+func (p *Melody) searchBranch(name, branch string) []types.Specification {
+	key := name + " " + branchPrefix + branch
+	if specs, ok := p.branches[key]; ok {
+		return specs
+	}
+
+	revision := branch
+	if p.base != nil {
+		locked, ok := p.base.PayloadFor(name).(*melodySpec)
+		if ok && locked.BranchName == branch && locked.Release != nil {
+			revision = locked.Revision()
+		}
+	}
+
+	specs, err := p.fetchBranch(name, branch, revision)
+	if err != nil {
+		log.Fatalf("Error fetching branch %s for %s: %s", branch, name, err)
+		return nil // TODO: Let's have an Err() accessor for Provider!
+	}
+
+	p.branches[key] = specs
+	return specs
+}
+
+// Current tip of a branch, ignoring any revision pinned in Melody.lock
+func (p *Melody) BranchTip(name, branch string) (types.Specification, error) {
+	specs, err := p.fetchBranch(name, branch, branch)
+	if err != nil || len(specs) == 0 {
+		return nil, err
+	}
+	return specs[len(specs)-1], nil
+}
+
+// Fetch specs at a revision and mark them as tracking the branch
+func (p *Melody) fetchBranch(name, branch, revision string) ([]types.Specification, error) {
+	pQuery := packageQuery{name: name, revisions: []string{revision}}
+	specs, err := p.fetchSpecs(&pQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spec := range specs {
+		spec.(*melodySpec).BranchName = branch
+	}
+	return specs, nil
+}
+
+// Number of API requests for resolver progress
+func (p *Melody) FetchCount() int {
+	return p.fetches
+}
+
+// Name of Melody.toml dependencies in conflict reports
+func (p *Melody) NameForExplicitDependencySource() string {
+	return "Melody.toml"
+}
+
+// Name of Melody.lock dependencies in conflict reports
+func (p *Melody) NameForLockingDependencySource() string {
+	return "Melody.lock"
+}
+
 func (p *Melody) DependenciesFor(spec types.Specification) types.Requirements {
 	return spec.Requirements()
 }
@@ -263,9 +389,14 @@ func (p *Melody) responseError(resp *http.Response) error {
 		return err
 	}
 
-	data := struct{ Error string }{}
+	data := struct {
+		Error  string
+		Errors gqlErrors
+	}{}
 	if err := json.Unmarshal(raw, &data); err != nil {
 		data.Error = "Response: " + string(raw)
+	} else if data.Error == "" {
+		data.Error = data.Errors.Error()
 	}
 
 	return fmt.Errorf("Server error: %s", data.Error)
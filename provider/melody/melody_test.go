@@ -0,0 +1,249 @@
+package melody
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/mdy/melody/resolver"
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/resolver/types"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+// Melody API answering queries from a list of versions
+type fakeAPI struct {
+	versions []*fakeVersion
+	noYanked bool // Schema without the yanked field
+}
+
+type fakeVersion struct {
+	name, version, revision string
+	yanked                  bool
+}
+
+var (
+	queryName     = regexp.MustCompile(`package\(name:"([^"]+)"\)`)
+	queryVersion  = regexp.MustCompile(`(v\d+): version\(version:"([^"]+)"\)`)
+	queryRevision = regexp.MustCompile(`(v\d+): version\(revision:"([^"]+)"\)`)
+)
+
+func (api *fakeAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.ParseForm()
+	query := req.PostForm.Get("query")
+	name := queryName.FindStringSubmatch(query)[1]
+
+	if api.noYanked && bytes.Contains([]byte(query), []byte("yanked")) {
+		raw := `{"errors": [{"message": "Cannot query field \"yanked\" on type \"Version\"."}]}`
+		return &http.Response{StatusCode: 400, Body: ioutil.NopCloser(bytes.NewReader([]byte(raw)))}, nil
+	}
+
+	pkg := map[string]interface{}{}
+	if bytes.Contains([]byte(query), []byte("versionList")) {
+		list := []interface{}{}
+		for _, v := range api.versions {
+			if v.name == name {
+				list = append(list, v.json())
+			}
+		}
+		pkg["versionList"] = list
+	}
+
+	for _, m := range queryVersion.FindAllStringSubmatch(query, -1) {
+		for _, v := range api.versions {
+			if v.name == name && v.version == m[2] {
+				pkg[m[1]] = v.json()
+			}
+		}
+	}
+
+	for _, m := range queryRevision.FindAllStringSubmatch(query, -1) {
+		for _, v := range api.versions {
+			if v.name == name && v.revision == m[2] {
+				pkg[m[1]] = v.json()
+			}
+		}
+	}
+
+	raw, _ := json.Marshal(map[string]interface{}{"data": map[string]interface{}{"package": pkg}})
+	return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader(raw))}, nil
+}
+
+func (v *fakeVersion) json() interface{} {
+	return map[string]interface{}{
+		"name": v.name, "version": v.version, "yanked": v.yanked,
+		"release": map[string]interface{}{
+			"name": v.name, "version": v.version, "revision": v.revision,
+			"url": fmt.Sprintf(melodyReleaseURL, v.name, v.revision),
+		},
+		"dependencyList": []interface{}{},
+	}
+}
+
+func fakeProvider(locked *resolver.Graph, versions ...*fakeVersion) *Melody {
+	source := New(locked)
+	source.client = &http.Client{Transport: &fakeAPI{versions: versions}}
+	return source
+}
+
+// Melody.lock decoder for graphs in tests
+type lockDecoder struct {
+	raw string
+	Builder
+}
+
+func (d *lockDecoder) Decode(v interface{}) error {
+	_, err := toml.Decode(d.raw, v)
+	return err
+}
+
+func decodeLock(t *testing.T, raw string) *resolver.Graph {
+	graph, err := resolver.DecodeGraph(&lockDecoder{raw: raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return graph
+}
+
+const branchLock = `
+[project]
+dependencies = ["github.com/x/lib 1.0.0"]
+
+[[packages]]
+name = "github.com/x/lib"
+version = "1.0.0"
+release = "github.com/x/lib#aaa"
+branch = "main"
+`
+
+func revisionOf(t *testing.T, specs []types.Specification) string {
+	if len(specs) != 1 {
+		t.Fatalf("expected a single spec, got %v", specs)
+	}
+	return specs[0].(*melodySpec).Revision()
+}
+
+func TestMelody_BranchUpdate(t *testing.T) {
+	locked := decodeLock(t, branchLock)
+	source := fakeProvider(locked,
+		&fakeVersion{name: "github.com/x/lib", version: "1.0.0", revision: "aaa"},
+		&fakeVersion{name: "github.com/x/lib", version: "1.1.0", revision: "main"},
+	)
+	req := source.NewRequirement("github.com/x/lib", "branch:main")
+
+	// Installs keep the revision locked in Melody.lock
+	if rev := revisionOf(t, source.SearchFor(req)); rev != "aaa" {
+		t.Errorf("locked branch resolved to %s, expected aaa", rev)
+	}
+
+	// Updates resolve without a lock, so the branch moves to its tip
+	source.SetBase(resolver.NewGraph())
+	if rev := revisionOf(t, source.SearchFor(req)); rev != "main" {
+		t.Errorf("updated branch resolved to %s, expected main", rev)
+	}
+
+	res := resolver.NewResolver(source, resolver.NewStdoutUI())
+	out, err := res.Resolve(types.Requirements{req}, resolver.NewGraph())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := out.PayloadFor("github.com/x/lib").Version(); v != "1.1.0" {
+		t.Errorf("update resolved to %s, expected 1.1.0", v)
+	}
+}
+
+const yankedLock = `
+[project]
+dependencies = ["github.com/x/lib 1.2.0"]
+
+[[packages]]
+name = "github.com/x/lib"
+version = "1.2.0"
+release = "github.com/x/lib#ccc"
+`
+
+func versionsOf(specs []types.Specification) []string {
+	out := []string{}
+	for _, s := range specs {
+		out = append(out, s.Version())
+	}
+	return out
+}
+
+func TestMelody_SearchForBanned(t *testing.T) {
+	locked := decodeLock(t, yankedLock)
+	source := fakeProvider(locked,
+		&fakeVersion{name: "github.com/x/lib", version: "1.0.0", revision: "aaa"},
+		&fakeVersion{name: "github.com/x/lib", version: "1.1.0", revision: "bbb"},
+		&fakeVersion{name: "github.com/x/lib", version: "1.2.0", revision: "ccc", yanked: true},
+	)
+	source.Exclude("github.com/x/lib", "1.1.0")
+	req := source.NewRequirement("github.com/x/lib", ">= 1.0")
+
+	// Yanked and excluded versions are only kept while they're locked
+	tests := []struct {
+		base     *resolver.Graph
+		expected string
+	}{
+		{locked, "[1.0.0 1.2.0]"},
+		{resolver.NewGraph(), "[1.0.0]"},
+		{nil, "[1.0.0]"},
+	}
+
+	for _, test := range tests {
+		source.SetBase(test.base)
+		if out := fmt.Sprint(versionsOf(source.SearchFor(req))); out != test.expected {
+			t.Errorf("SearchFor(%s) = %s, expected %s", req, out, test.expected)
+		}
+	}
+}
+
+func TestMelody_SearchForWithoutYanked(t *testing.T) {
+	source := fakeProvider(nil,
+		&fakeVersion{name: "github.com/x/lib", version: "1.0.0", revision: "aaa"},
+		&fakeVersion{name: "github.com/x/lib", version: "1.1.0", revision: "bbb"},
+		&fakeVersion{name: "github.com/x/other", version: "2.0.0", revision: "ccc"},
+	)
+	source.client.Transport.(*fakeAPI).noYanked = true
+
+	// Registries without yanked versions are queried again without them, once
+	tests := map[string]string{"github.com/x/lib": "[1.0.0 1.1.0]", "github.com/x/other": "[2.0.0]"}
+	for name, expected := range tests {
+		req := source.NewRequirement(name, ">= 1.0")
+		if out := fmt.Sprint(versionsOf(source.SearchFor(req))); out != expected {
+			t.Errorf("SearchFor(%s) = %s, expected %s", req, out, expected)
+		}
+	}
+	if source.fetches != 3 {
+		t.Errorf("expected 3 fetches, got %d", source.fetches)
+	}
+}
+
+func TestMelodyRequirement_Revision(t *testing.T) {
+	spec := func(v, rev string) *melodySpec {
+		fs := flex.Specification{NameStr: "github.com/x/lib", VersStr: v}
+		return &melodySpec{Specification: fs, Release: &melodyRelease{Revision: rev}}
+	}
+
+	cases := []struct {
+		spec *melodySpec
+		ok   bool
+	}{
+		{spec("1.0.0", "abcdef1234567"), true},
+		{spec("1.0.0", "bbbbbbb"), false},
+		{spec("not a version", "bbbbbbb"), false},
+		{spec("0.0.0-20200101000000-abcdef123456", ""), true},
+		{spec("0.0.0-20200101000000-bbbbbbbbbbbb", ""), false},
+	}
+
+	req := &melodyRequirement{flex.NewDependency("github.com/x/lib", "#abcdef1234567")}
+	for _, c := range cases {
+		ok, err := req.SatisfiedBy(c.spec)
+		if err != nil || ok != c.ok {
+			t.Errorf("%s#%s: got %t, %v; want %t", c.spec.Version(), c.spec.Revision(), ok, err, c.ok)
+		}
+	}
+}
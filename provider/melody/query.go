@@ -5,11 +5,26 @@ import (
 	"fmt"
 	"github.com/mdy/melody/resolver/types"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"io/ioutil"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
+// Errors in a GraphQL response
+type gqlErrors []struct {
+	Message string `json:"message"`
+}
+
+func (e gqlErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
 const (
 	// melodyAPI GraphURL endpoint
 	melodyURL = "https://api.melody.sh/graphql"
@@ -18,8 +33,21 @@ const (
 	melodyReleaseURL = "https://api.melody.sh/%s/-/%s/tgz"
 )
 
+// Fetch specs, without yanked flags if the registry doesn't know about them
 func (p *Melody) fetchSpecs(query *packageQuery) ([]types.Specification, error) {
+	query.withoutYanked = p.withoutYanked
+	specs, err := p.fetchQuery(query)
+	if err != nil && !p.withoutYanked && strings.Contains(err.Error(), "yanked") {
+		log.Warn("Registry has no yanked versions, querying without them: ", err)
+		p.withoutYanked = true
+		return p.fetchSpecs(query)
+	}
+	return specs, err
+}
+
+func (p *Melody) fetchQuery(query *packageQuery) ([]types.Specification, error) {
 	// Populate arguments into query and send it to Melody-API
+	p.fetches++
 	resp, err := p.client.PostForm(melodyURL, url.Values{"query": {query.GqlString()}})
 	if err != nil {
 		return nil, err
@@ -40,10 +68,13 @@ func (p *Melody) fetchSpecs(query *packageQuery) ([]types.Specification, error)
 		Data struct {
 			Package map[string]json.RawMessage
 		}
+		Errors gqlErrors
 	}{}
 
 	if err := json.Unmarshal(raw, &respJSON); err != nil {
 		return nil, err
+	} else if respJSON.Data.Package == nil && len(respJSON.Errors) > 0 {
+		return nil, fmt.Errorf("Server error: %s", respJSON.Errors)
 	}
 
 	// Let's unmarshall everything one by one
@@ -80,10 +111,11 @@ func (p *Melody) fetchSpecs(query *packageQuery) ([]types.Specification, error)
 }
 
 type packageQuery struct {
-	name      string
-	allTagged bool
-	revisions []string
-	versions  []string
+	name          string
+	allTagged     bool
+	revisions     []string
+	versions      []string
+	withoutYanked bool
 }
 
 func (q *packageQuery) GqlString() string {
@@ -103,13 +135,18 @@ func (q *packageQuery) GqlString() string {
 		vCount++
 	}
 
-	return fmt.Sprintf(gqlPackageQuery, strconv.QuoteToASCII(q.name), query)
+	yanked := gqlYankedField
+	if q.withoutYanked {
+		yanked = ""
+	}
+	return fmt.Sprintf(gqlPackageQuery, strconv.QuoteToASCII(q.name), query, yanked)
 }
 
 const (
 	gqlAllTaggedVersions = "versionList { ...VersionInfo }\n"
 	gqlVersionByName     = "v%d: version(version:%s) { ...VersionInfo }\n"
 	gqlVersionByRev      = "v%d: version(revision:%s) { ...VersionInfo }\n"
+	gqlYankedField       = " yanked,"
 	gqlPackageQuery      = `
     query PackageQuery {
       package(name:%s) {
@@ -117,7 +154,7 @@ const (
       }
     }
     fragment VersionInfo on Version {
-      name, version,
+      name, version,%s
       release { name, version, revision, url },
       dependencyList(scope:BUILD) { name, versionRange }
     }
@@ -5,6 +5,7 @@ import (
 	"github.com/mdy/melody/resolver"
 	"github.com/mdy/melody/resolver/flex"
 	"github.com/mdy/melody/resolver/types"
+	"github.com/mdy/melody/version"
 
 	"encoding/json"
 	"fmt"
@@ -22,6 +23,7 @@ type Builder struct{}
 
 func (b *Builder) NewSpec(i *resolver.GraphItem) (types.Specification, error) {
 	spec := &melodySpec{Specification: *(flex.NewSpec(i.Name, i.Version))}
+	spec.BranchName = i.Branch
 
 	if j := strings.Index(i.Release, "#"); j >= 0 {
 		name, rev := i.Release[0:j], i.Release[j+1:]
@@ -39,6 +41,8 @@ type melodySpec struct {
 	flex.Specification
 	Release        *melodyRelease
 	DependencyList melodyRequirements
+	BranchName     string `json:"-"`
+	Yanked         bool
 }
 
 func (ms *melodySpec) Requirements() types.Requirements {
@@ -47,11 +51,35 @@ func (ms *melodySpec) Requirements() types.Requirements {
 
 // Revisioned interface
 func (ms *melodySpec) Revision() string {
+	if ms.Release == nil {
+		return ""
+	}
 	return ms.Release.Revision
 }
 
+// Branch tracked by the spec, empty if it was not resolved from a branch
+func (ms *melodySpec) Branch() string {
+	return ms.BranchName
+}
+
+// Version was yanked (or retracted) from the registry
+func (ms *melodySpec) IsYanked() bool {
+	return ms.Yanked
+}
+
+// Download URL of the release
+func (ms *melodySpec) Source() string {
+	if ms.Release == nil {
+		return ""
+	}
+	return ms.Release.URL
+}
+
 // Implement resolver.Released interface
 func (ms *melodySpec) ReleaseSpec() provider.ReleaseSpec {
+	if ms.Release == nil {
+		return nil // Not a nil *melodyRelease
+	}
 	return ms.Release
 }
 
@@ -101,10 +129,19 @@ func (p *Melody) NewRequirement(n, v string) types.Requirement {
 }
 
 // Melody requirement that allows "head" meaning "latest release or beta"
+// and "branch:NAME" meaning the tip of a branch when it was last updated
 type melodyRequirement struct {
 	*flex.Dependency
 }
 
+// Name of the tracked branch, if the requirement is "branch:NAME"
+func (s *melodyRequirement) Branch() (string, bool) {
+	if !strings.HasPrefix(s.RangeStr, branchPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(s.RangeStr, branchPrefix), true
+}
+
 func (s *melodyRequirement) SatisfiedBy(spec types.Specification) (bool, error) {
 	if s.NameStr != spec.Name() {
 		return false, nil
@@ -114,15 +151,34 @@ func (s *melodyRequirement) SatisfiedBy(spec types.Specification) (bool, error)
 		return true, nil
 	}
 
-	if strings.HasPrefix(s.RangeStr, "#") {
-		if r, ok := spec.(Revisioned); ok {
-			return s.RangeStr[1:] == r.Revision(), nil
+	// Only specs resolved from the same branch
+	if branch, ok := s.Branch(); ok {
+		mSpec, isMelody := spec.(*melodySpec)
+		return isMelody && mSpec.BranchName == branch, nil
+	}
+
+	// Same revision, or a pseudo-version made from it
+	if rev := strings.TrimPrefix(s.RangeStr, "#"); rev != s.RangeStr {
+		if r, ok := spec.(Revisioned); ok && rev == r.Revision() {
+			return true, nil
+		}
+		if v, err := flex.ParseVersion(spec.Version()); err == nil && v.IsPseudo() {
+			return v.MatchesRevision(rev), nil
 		}
+		return false, nil
 	}
 
 	return s.Dependency.SatisfiedBy(spec)
 }
 
+// Versions allowed by the requirement, where "head", branches and revisions allow any
+func (s *melodyRequirement) VersionSet() (version.Set, error) {
+	if _, ok := s.Branch(); ok || s.RangeStr == "head" || s.RangeStr == "**" || strings.HasPrefix(s.RangeStr, "#") {
+		return version.Set{version.Interval{}}, nil
+	}
+	return s.Dependency.VersionSet()
+}
+
 // Melody requirement marshalling and initialization
 type melodyRequirements types.Requirements
 
@@ -24,3 +24,26 @@ type ReleaseSpec interface {
 	InstallPath() string
 	types.Specification
 }
+
+// Specification for a version that may be yanked from its registry
+type YankedSpec interface {
+	IsYanked() bool
+	types.Specification
+}
+
+// Specification resolved from the tip of a branch
+type BranchSpec interface {
+	Branch() string
+	Revision() string
+	types.Specification
+}
+
+// Provider that can look up the current tip of a branch
+type BranchProvider interface {
+	BranchTip(name, branch string) (types.Specification, error)
+}
+
+// Provider that keeps packages locked in the base graph of a resolution
+type BaseGraphProvider interface {
+	SetBase(base *resolver.Graph)
+}
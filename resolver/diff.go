@@ -0,0 +1,155 @@
+package resolver
+
+import (
+	"github.com/mdy/melody/version"
+	"sort"
+	"strings"
+)
+
+// Package that differs between two graphs
+type ChangedSpec struct {
+	Name        string `json:"name"`
+	OldVersion  string `json:"oldVersion,omitempty"`
+	NewVersion  string `json:"newVersion,omitempty"`
+	OldRevision string `json:"oldRevision,omitempty"`
+	NewRevision string `json:"newRevision,omitempty"`
+}
+
+// Dependency of one package on another
+type DependencyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Changed packages between two graphs by kind, and changed dependencies
+// between packages, sorted by name
+type GraphDiff struct {
+	Added        []*ChangedSpec    `json:"added"`
+	Removed      []*ChangedSpec    `json:"removed"`
+	Upgraded     []*ChangedSpec    `json:"upgraded"`
+	Downgraded   []*ChangedSpec    `json:"downgraded"`
+	Revised      []*ChangedSpec    `json:"revised"` // Same version, other revision
+	AddedEdges   []*DependencyEdge `json:"addedEdges"`
+	RemovedEdges []*DependencyEdge `json:"removedEdges"`
+}
+
+// Compare packages of this graph with a newer one.  Releases are
+// part of their packages, so only their revisions are compared
+func (g *Graph) Diff(newer *Graph, p version.Parser) *GraphDiff {
+	oldSpecs, newSpecs := g.packageVertices(), newer.packageVertices()
+	names := []string{}
+	for name := range oldSpecs {
+		names = append(names, name)
+	}
+	for name := range newSpecs {
+		if _, ok := oldSpecs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diff := &GraphDiff{
+		Added:      []*ChangedSpec{},
+		Removed:    []*ChangedSpec{},
+		Upgraded:   []*ChangedSpec{},
+		Downgraded: []*ChangedSpec{},
+		Revised:    []*ChangedSpec{},
+	}
+
+	for _, name := range names {
+		oldV, newV := oldSpecs[name], newSpecs[name]
+		change := &ChangedSpec{Name: name}
+		if oldV != nil {
+			change.OldVersion = oldV.Payload.Version()
+			change.OldRevision = revisionOf(oldV)
+		}
+		if newV != nil {
+			change.NewVersion = newV.Payload.Version()
+			change.NewRevision = revisionOf(newV)
+		}
+
+		// Versions spelled differently (1.0 and v1.0.0) are the same version
+		switch {
+		case oldV == nil:
+			diff.Added = append(diff.Added, change)
+		case newV == nil:
+			diff.Removed = append(diff.Removed, change)
+		case compareVersions(p, change.OldVersion, change.NewVersion) > 0:
+			diff.Downgraded = append(diff.Downgraded, change)
+		case compareVersions(p, change.OldVersion, change.NewVersion) < 0:
+			diff.Upgraded = append(diff.Upgraded, change)
+		case change.OldRevision != change.NewRevision && change.OldRevision != "" && change.NewRevision != "":
+			diff.Revised = append(diff.Revised, change)
+		}
+	}
+
+	diff.AddedEdges = newer.packageEdges().without(g.packageEdges())
+	diff.RemovedEdges = g.packageEdges().without(newer.packageEdges())
+	return diff
+}
+
+// No package or dependency changed
+func (d *GraphDiff) IsEmpty() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Upgraded)+len(d.Downgraded)+len(d.Revised) == 0 &&
+		len(d.AddedEdges)+len(d.RemovedEdges) == 0
+}
+
+type edgeSet map[DependencyEdge]bool
+
+// Edges between activated packages, without releases
+func (g *Graph) packageEdges() edgeSet {
+	edges := edgeSet{}
+	for _, e := range g.Edges() {
+		from, to := e.From().(*Vertex), e.To().(*Vertex)
+		if from.Payload != nil && to.Payload != nil &&
+			!isReleaseName(from.Name) && !isReleaseName(to.Name) {
+			edges[DependencyEdge{from.Name, to.Name}] = true
+		}
+	}
+	return edges
+}
+
+// Sorted edges that are not in the other set
+func (s edgeSet) without(other edgeSet) []*DependencyEdge {
+	out := []*DependencyEdge{}
+	for edge := range s {
+		if !other[edge] {
+			edge := edge
+			out = append(out, &edge)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		return a.From < b.From || (a.From == b.From && a.To < b.To)
+	})
+	return out
+}
+
+// Activated package vertices by name, without releases
+func (g *Graph) packageVertices() map[string]*Vertex {
+	vertices := map[string]*Vertex{}
+	for _, n := range g.Nodes() {
+		if v := n.(*Vertex); v.Payload != nil && !isReleaseName(v.Name) {
+			vertices[v.Name] = v
+		}
+	}
+	return vertices
+}
+
+func revisionOf(v *Vertex) string {
+	if r, ok := v.Payload.(revisioned); ok {
+		return r.Revision()
+	}
+	return ""
+}
+
+// Unparsable versions are compared as strings
+func compareVersions(p version.Parser, a, b string) int {
+	vA, errA := p.Parse(a)
+	vB, errB := p.Parse(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return vA.Compare(vB)
+}
@@ -0,0 +1,72 @@
+package resolver
+
+import (
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/resolver/rubygem"
+	c "gopkg.in/check.v1"
+)
+
+// Gem pinned to a revision, like packages from Melody.lock
+type revisionedGem struct {
+	*rubygem.Specification
+	revision string
+}
+
+func (s *revisionedGem) Revision() string { return s.revision }
+
+func (s *MySuite) Test_Graph_Diff(t *c.C) {
+	old := NewGraph()
+	old.addVertex("kept", gemSpec("kept", "1.0.0"), true)
+	old.addVertex("gone", gemSpec("gone", "0.1.0"), true)
+	old.addVertex("up", gemSpec("up", "1.9.0"), true)
+	old.addVertex("down", gemSpec("down", "2.0.0"), true)
+	old.addVertex("head", &revisionedGem{gemSpec("head", "1.0.0"), "abc"}, true)
+
+	newer := NewGraph()
+	newer.addVertex("kept", gemSpec("kept", "1.0.0"), true)
+	newer.addVertex("new", gemSpec("new", "3.0.0"), true)
+	newer.addVertex("up", gemSpec("up", "1.10.0"), true)
+	newer.addVertex("down", gemSpec("down", "1.5.0"), true)
+	newer.addVertex("head", &revisionedGem{gemSpec("head", "1.0.0"), "def"}, true)
+	newer.addVertex("repo://new", gemSpec("repo://new", "3.0.0"), false)
+
+	// Dependencies between packages, releases are left out
+	old.addChildVertex("up", nil, []string{"kept"}, nil)
+	old.addChildVertex("gone", nil, []string{"kept"}, nil)
+	newer.addChildVertex("up", nil, []string{"kept"}, nil)
+	newer.addChildVertex("new", nil, []string{"kept"}, nil)
+	newer.addChildVertex("repo://new", nil, []string{"new"}, nil)
+
+	diff := old.Diff(newer, rubygem.VersionParser)
+	t.Assert(diff.Added, c.DeepEquals, []*ChangedSpec{{Name: "new", NewVersion: "3.0.0"}})
+	t.Assert(diff.Removed, c.DeepEquals, []*ChangedSpec{{Name: "gone", OldVersion: "0.1.0"}})
+	t.Assert(diff.Upgraded, c.DeepEquals, []*ChangedSpec{{Name: "up", OldVersion: "1.9.0", NewVersion: "1.10.0"}})
+	t.Assert(diff.Downgraded, c.DeepEquals, []*ChangedSpec{{Name: "down", OldVersion: "2.0.0", NewVersion: "1.5.0"}})
+	t.Assert(diff.Revised, c.DeepEquals, []*ChangedSpec{{
+		Name: "head", OldVersion: "1.0.0", NewVersion: "1.0.0", OldRevision: "abc", NewRevision: "def",
+	}})
+	t.Assert(diff.AddedEdges, c.DeepEquals, []*DependencyEdge{{From: "kept", To: "new"}})
+	t.Assert(diff.RemovedEdges, c.DeepEquals, []*DependencyEdge{{From: "kept", To: "gone"}})
+	t.Assert(diff.IsEmpty(), c.Equals, false)
+	t.Assert(old.Diff(old, rubygem.VersionParser).IsEmpty(), c.Equals, true)
+}
+
+func (s *MySuite) Test_Graph_DiffSameVersion(t *c.C) {
+	old := NewGraph()
+	old.addVertex("short", gemSpec("short", "1.0"), true)
+	old.addVertex("prefixed", gemSpec("prefixed", "v1.2.0"), true)
+	old.addVertex("head", &revisionedGem{gemSpec("head", "1.0"), "abc"}, true)
+
+	newer := NewGraph()
+	newer.addVertex("short", gemSpec("short", "1.0.0"), true)
+	newer.addVertex("prefixed", gemSpec("prefixed", "1.2.0"), true)
+	newer.addVertex("head", &revisionedGem{gemSpec("head", "1.0.0"), "def"}, true)
+
+	// Versions that are only spelled differently are not upgrades
+	diff := old.Diff(newer, flex.VersionParser)
+	t.Assert(diff.Upgraded, c.HasLen, 0)
+	t.Assert(diff.Downgraded, c.HasLen, 0)
+	t.Assert(diff.Revised, c.DeepEquals, []*ChangedSpec{{
+		Name: "head", OldVersion: "1.0", NewVersion: "1.0.0", OldRevision: "abc", NewRevision: "def",
+	}})
+}
@@ -28,6 +28,7 @@ type GraphItem struct {
 	Name    string `toml:"name,omitempty"`
 	Version string `toml:"version,omitempty"`
 	Release string `toml:"release,omitempty"`
+	Branch  string `toml:"branch,omitempty"`
 }
 
 func (i *GraphItem) id() string {
@@ -39,6 +40,10 @@ type revisioned interface {
 	Revision() string
 }
 
+type branched interface {
+	Branch() string
+}
+
 type released interface {
 	ReleaseSpec() types.Specification
 }
@@ -66,6 +71,7 @@ func DecodeGraph(decoder GraphDecoder) (*Graph, error) {
 
 	// Recuse through all items and populate graph
 	// Each graph item is only added once from itemMap
+	visited := map[*encodedItem]bool{}
 	var addFunc func(graph *Graph, item *encodedItem, root bool) error
 	addFunc = func(graph *Graph, item *encodedItem, root bool) error {
 		for _, depID := range item.Dependencies {
@@ -90,7 +96,11 @@ func DecodeGraph(decoder GraphDecoder) (*Graph, error) {
 				graph.addChildVertex(release.Name(), release, parent, nil)
 			}
 
-			// Add recurse to traverse graph via dependencies
+			// Add recurse to traverse graph via dependencies (once, cycles are allowed)
+			if visited[childItem] {
+				continue
+			}
+			visited[childItem] = true
 			if err := addFunc(graph, childItem, false); err != nil {
 				return err
 			}
@@ -112,6 +122,9 @@ func (g *Graph) Encode(encoder GraphEncoder) error {
 	for _, n := range g.Nodes() {
 		v, s := n.(*Vertex), n.(*Vertex).Payload
 		gItem := GraphItem{Name: s.Name(), Version: s.Version()}
+		if b, ok := s.(branched); ok {
+			gItem.Branch = b.Branch()
+		}
 		item := &encodedItem{GraphItem: gItem}
 		item.Dependencies = []string{}
 		itemMap[v] = item
@@ -3,16 +3,37 @@ package resolver
 import (
 	"fmt"
 	"github.com/mdy/melody/resolver/types"
+	"strings"
 )
 
 // Resolver error to indicate a circular dependency
 type CircularDependencyError struct {
-	Src *Vertex
-	Dst *Vertex
+	Src   *Vertex
+	Dst   *Vertex
+	Cycle []string // Names from Dst around the cycle back to Dst
 }
 
 func (e *CircularDependencyError) Error() string {
-	return fmt.Sprintf("CircularDependencyError(%s, %s)", e.Src.Name, e.Dst.Name)
+	return fmt.Sprintf("CircularDependencyError(%s, %s): %s",
+		e.Src.Name, e.Dst.Name, strings.Join(e.Cycle, " -> "))
+}
+
+// Resolver error to indicate dependencies without a specification
+type UnresolvedError struct {
+	Names []string
+}
+
+func (e *UnresolvedError) Error() string {
+	return fmt.Sprintf("UnresolvedError: no specification for %s", strings.Join(e.Names, ", "))
+}
+
+// Resolver error to indicate an incomplete or inconsistent graph
+type InvalidGraphError struct {
+	Problems []string
+}
+
+func (e *InvalidGraphError) Error() string {
+	return "InvalidGraphError: \n  " + strings.Join(e.Problems, "\n  ") + "\n"
 }
 
 // Resolver error to indicate version conflict
@@ -23,6 +44,9 @@ func (e *VersionConflictError) Error() string {
 	for name, c := range Conflicts(*e) {
 		s += "  Could not find compatible versions for"
 		s += " \"" + name + "\":\n    " + c.Requirement.String() + "\n"
+		if len(c.Unsatisfiable) > 0 {
+			s += "    no version satisfies " + strings.Join(c.Unsatisfiable, " ") + "\n"
+		}
 		for _, branch := range c.RequirementTrees {
 			if len(branch) == 0 {
 				continue
@@ -38,6 +62,12 @@ func (e *VersionConflictError) Error() string {
 				s += "\n"
 			}
 		}
+		if len(c.Suggestions) > 0 {
+			s += "\n  Suggestions:\n"
+			for _, suggestion := range c.Suggestions {
+				s += "    - " + suggestion + "\n"
+			}
+		}
 	}
 	return s //fmt.Sprintf("VersionConflictError: %s", Conflicts(*e))
 }
@@ -63,4 +93,7 @@ type Conflict struct {
 	RequirementTrees  [][]types.Requirement
 	ActivatedByName   map[string]types.Specification
 	Requirements      map[string][]types.Requirement
+	Candidates        []types.Specification
+	Suggestions       []string
+	Unsatisfiable     []string // Constraints without any common version
 }
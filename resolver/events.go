@@ -0,0 +1,92 @@
+package resolver
+
+import (
+	"encoding/json"
+	"github.com/mdy/melody/resolver/types"
+	"io"
+)
+
+// Hooks for tools that follow a resolution as it happens
+type Events interface {
+	// Requirement was looked up with a number of possibilities
+	OnSearch(types.Requirement, int)
+
+	// Specification was activated in the dependency graph
+	OnActivate(types.Specification)
+
+	// Requirement of a named dependency could not be met
+	OnConflict(string, *Conflict)
+
+	// Resolution backtracked between state depths (-1 when exhausted)
+	OnBacktrack(int, int)
+
+	// Resolution is finished, the graph is nil if it failed
+	OnComplete(*Graph, *Stats)
+}
+
+// No-op implementation to embed for partial hooks
+type BaseEvents struct{}
+
+func (e *BaseEvents) OnSearch(types.Requirement, int) {}
+func (e *BaseEvents) OnActivate(types.Specification)  {}
+func (e *BaseEvents) OnConflict(string, *Conflict)    {}
+func (e *BaseEvents) OnBacktrack(int, int)            {}
+func (e *BaseEvents) OnComplete(*Graph, *Stats)       {}
+
+// Events as JSON lines that can be consumed by other tools
+func NewJSONEvents(w io.Writer) Events {
+	return &jsonEvents{encoder: json.NewEncoder(w)}
+}
+
+type jsonEvents struct {
+	encoder *json.Encoder
+}
+
+type jsonEvent struct {
+	Event       string   `json:"event"`
+	Name        string   `json:"name,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Requirement string   `json:"requirement,omitempty"`
+	Count       *int     `json:"count,omitempty"`
+	FromDepth   *int     `json:"fromDepth,omitempty"`
+	ToDepth     *int     `json:"toDepth,omitempty"`
+	Success     *bool    `json:"success,omitempty"`
+	Stats       *Stats   `json:"stats,omitempty"`
+	Resolved    []string `json:"resolved,omitempty"`
+}
+
+func (e *jsonEvents) emit(event *jsonEvent) {
+	e.encoder.Encode(event)
+}
+
+func (e *jsonEvents) OnSearch(req types.Requirement, count int) {
+	e.emit(&jsonEvent{Event: "search", Name: req.Name(), Requirement: constraintFor(req), Count: &count})
+}
+
+func (e *jsonEvents) OnActivate(spec types.Specification) {
+	e.emit(&jsonEvent{Event: "activate", Name: spec.Name(), Version: spec.Version()})
+}
+
+func (e *jsonEvents) OnConflict(name string, c *Conflict) {
+	event := &jsonEvent{Event: "conflict", Name: name}
+	if c.Requirement != nil {
+		event.Requirement = constraintFor(c.Requirement)
+	}
+	e.emit(event)
+}
+
+func (e *jsonEvents) OnBacktrack(fromDepth, toDepth int) {
+	e.emit(&jsonEvent{Event: "backtrack", FromDepth: &fromDepth, ToDepth: &toDepth})
+}
+
+func (e *jsonEvents) OnComplete(graph *Graph, stats *Stats) {
+	success := graph != nil
+	event := &jsonEvent{Event: "complete", Success: &success, Stats: stats}
+	if graph != nil {
+		event.Resolved = []string{}
+		for _, s := range graph.Specifications() {
+			event.Resolved = append(event.Resolved, s.Name()+" "+s.Version())
+		}
+	}
+	e.emit(event)
+}
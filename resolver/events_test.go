@@ -0,0 +1,63 @@
+package resolver
+
+import (
+	"bytes"
+	"github.com/mdy/melody/resolver/types"
+	c "gopkg.in/check.v1"
+	"strings"
+)
+
+type recordingEvents struct {
+	BaseEvents
+	activated []string
+	completed *Graph
+	searches  int
+}
+
+func (e *recordingEvents) OnSearch(types.Requirement, int) {
+	e.searches++
+}
+
+func (e *recordingEvents) OnActivate(spec types.Specification) {
+	e.activated = append(e.activated, spec.Name()+" "+spec.Version())
+}
+
+func (e *recordingEvents) OnComplete(graph *Graph, stats *Stats) {
+	e.completed = graph
+}
+
+func (s *MySuite) Test_Events_Hooks(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", "~> 1.0"),
+		gemSpec("lib", "1.0.0"),
+		gemSpec("lib", "2.0.0"),
+	)
+
+	events := &recordingEvents{}
+	resolver := NewResolver(provider, &silentUI{})
+	resolver.Events = events
+
+	graph, err := resolver.Resolve(types.Requirements{gemDependency("app", ">= 0")}, nil)
+	t.Assert(err, c.IsNil)
+	t.Assert(events.completed, c.Equals, graph)
+	t.Assert(events.activated, c.DeepEquals, []string{"app 1.0.0", "lib 1.0.0"})
+	t.Assert(events.searches > 0, c.Equals, true)
+}
+
+func (s *MySuite) Test_Events_JSON(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", "~> 3.0"),
+		gemSpec("lib", "1.0.0"),
+	)
+
+	var buffer bytes.Buffer
+	resolver := NewResolver(provider, &silentUI{})
+	resolver.Events = NewJSONEvents(&buffer)
+
+	_, err := resolver.Resolve(types.Requirements{gemDependency("app", ">= 0")}, nil)
+	t.Assert(err, c.NotNil)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	t.Assert(strings.Contains(buffer.String(), `"event":"conflict","name":"lib"`), c.Equals, true)
+	t.Assert(lines[len(lines)-1], c.Matches, `\{"event":"complete","success":false,.*`)
+}
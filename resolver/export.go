@@ -0,0 +1,212 @@
+package resolver
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Graph of packages for diagrams and external tools
+type ExportedGraph struct {
+	Nodes []*ExportedNode `json:"nodes"`
+	Edges []*ExportedEdge `json:"edges"`
+}
+
+type ExportedNode struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Root     bool   `json:"root,omitempty"`
+	Depth    int    `json:"depth"`
+	Outdated bool   `json:"outdated,omitempty"`
+	Newest   string `json:"newest,omitempty"`
+}
+
+type ExportedEdge struct {
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	Requirements []string `json:"requirements,omitempty"`
+}
+
+// Options to select and group vertices of an exported graph
+type ExportOptions struct {
+	Match  func(name string) bool // Nodes to keep (by exported name)
+	Depth  int                    // Deepest level to keep, roots are 1 (0 keeps all)
+	Group  func(*Vertex) string   // Exported node name, or "" to skip a vertex
+	Newest func(*Vertex) string   // Newer version of an outdated vertex, or ""
+}
+
+// Export activated vertices, grouping vertices with the same exported name
+// into one node.  Depth is the shortest distance from a root
+func (g *Graph) Export(opts *ExportOptions) *ExportedGraph {
+	depths := g.depths()
+	nodes, edges := map[string]*ExportedNode{}, map[[2]string]*ExportedEdge{}
+	names := map[*Vertex]string{}
+
+	for _, n := range g.Nodes() {
+		v := n.(*Vertex)
+		if v.Payload == nil || depths[v.Name] == 0 || (opts.Depth > 0 && depths[v.Name] > opts.Depth) {
+			continue
+		}
+
+		name := v.Name
+		if opts.Group != nil {
+			name = opts.Group(v)
+		}
+		if name == "" || (opts.Match != nil && !opts.Match(name)) {
+			continue
+		}
+		names[v] = name
+
+		node := nodes[name]
+		if node == nil {
+			node = &ExportedNode{Name: name, Version: v.Payload.Version(), Depth: depths[v.Name]}
+			nodes[name] = node
+		}
+		node.Root = node.Root || v.Root
+		if depths[v.Name] < node.Depth {
+			node.Depth = depths[v.Name]
+		}
+		if opts.Newest != nil && !node.Outdated {
+			node.Newest = opts.Newest(v)
+			node.Outdated = node.Newest != ""
+		}
+	}
+
+	// Edges between kept nodes, with the distinct requirements between them
+	for _, e := range g.Edges() {
+		from, to := names[e.From().(*Vertex)], names[e.To().(*Vertex)]
+		if from == "" || to == "" || from == to {
+			continue
+		}
+
+		key := [2]string{from, to}
+		edge := edges[key]
+		if edge == nil {
+			edge = &ExportedEdge{From: from, To: to}
+			edges[key] = edge
+		}
+
+		req := e.(*Edge).Requirement
+		if req != nil && !containsName(edge.Requirements, constraintFor(req)) {
+			edge.Requirements = append(edge.Requirements, constraintFor(req))
+		}
+	}
+
+	out := &ExportedGraph{Nodes: []*ExportedNode{}, Edges: []*ExportedEdge{}}
+	for _, node := range nodes {
+		out.Nodes = append(out.Nodes, node)
+	}
+	for _, edge := range edges {
+		sort.Strings(edge.Requirements)
+		out.Edges = append(out.Edges, edge)
+	}
+
+	sort.Slice(out.Nodes, func(i, j int) bool { return out.Nodes[i].Name < out.Nodes[j].Name })
+	sort.Slice(out.Edges, func(i, j int) bool {
+		a, b := out.Edges[i], out.Edges[j]
+		return a.From < b.From || (a.From == b.From && a.To < b.To)
+	})
+	return out
+}
+
+// Shortest distance of every reachable vertex from a root (roots are 1)
+func (g *Graph) depths() map[string]int {
+	depths, queue := map[string]int{}, []string{}
+	for _, n := range verticesByName(g.Nodes()) {
+		if v := n.(*Vertex); v.Root {
+			depths[v.Name] = 1
+			queue = append(queue, v.Name)
+		}
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, child := range g.node(name).children {
+			if _, seen := depths[child]; !seen {
+				depths[child] = depths[name] + 1
+				queue = append(queue, child)
+			}
+		}
+	}
+	return depths
+}
+
+// Graphviz DOT with roots drawn twice outlined and outdated nodes filled
+func (e *ExportedGraph) WriteDOT(w io.Writer) error {
+	lines := []string{"digraph dependencies {", "  node [shape=box];"}
+	for _, n := range e.Nodes {
+		attrs := fmt.Sprintf("label=%q", n.Name+"\n"+n.label())
+		if n.Root {
+			attrs += ", peripheries=2"
+		}
+		if n.Outdated {
+			attrs += `, style=filled, fillcolor="#ffd7d7"`
+		}
+		lines = append(lines, fmt.Sprintf("  %q [%s];", n.Name, attrs))
+	}
+
+	for _, edge := range e.Edges {
+		if label := edge.label(); label == "" {
+			lines = append(lines, fmt.Sprintf("  %q -> %q;", edge.From, edge.To))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %q -> %q [label=%q];", edge.From, edge.To, label))
+		}
+	}
+
+	_, err := io.WriteString(w, strings.Join(append(lines, "}"), "\n")+"\n")
+	return err
+}
+
+// Mermaid flowchart, which can be embedded in Markdown documents
+func (e *ExportedGraph) WriteMermaid(w io.Writer) error {
+	ids := map[string]string{}
+	lines, outdated := []string{"graph TD"}, []string{}
+	for i, n := range e.Nodes {
+		ids[n.Name] = fmt.Sprintf("n%d", i)
+		shape := `["%s"]`
+		if n.Root {
+			shape = `[["%s"]]`
+		}
+		label := mermaidEscape(n.Name + " " + n.label())
+		lines = append(lines, "  "+ids[n.Name]+fmt.Sprintf(shape, label))
+		if n.Outdated {
+			outdated = append(outdated, ids[n.Name])
+		}
+	}
+
+	for _, edge := range e.Edges {
+		arrow := " --> "
+		if label := edge.label(); label != "" {
+			arrow = ` -->|"` + mermaidEscape(label) + `"| `
+		}
+		lines = append(lines, "  "+ids[edge.From]+arrow+ids[edge.To])
+	}
+
+	if len(outdated) > 0 {
+		lines = append(lines, "  classDef outdated fill:#ffd7d7,stroke:#cc0000")
+		lines = append(lines, "  class "+strings.Join(outdated, ",")+" outdated")
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// Version, and the newest version when outdated
+func (n *ExportedNode) label() string {
+	if n.Outdated {
+		return n.Version + " (newest " + n.Newest + ")"
+	}
+	return n.Version
+}
+
+// Requirements between two nodes
+func (e *ExportedEdge) label() string {
+	return strings.Join(e.Requirements, " | ")
+}
+
+// Characters with a meaning in Mermaid labels as entity codes
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
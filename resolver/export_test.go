@@ -0,0 +1,70 @@
+package resolver
+
+import (
+	"bytes"
+	"strings"
+
+	c "gopkg.in/check.v1"
+)
+
+func exportTestGraph() *Graph {
+	graph := NewGraph()
+	graph.addVertex("a/x", gemSpec("a/x", "1.0.0"), true)
+	graph.addChildVertex("b/x", gemSpec("b/x", "2.0.0"), []string{"a/x"}, gemDependency("b/x", ">= 2.0"))
+	graph.addChildVertex("b/y", gemSpec("b/y", "2.0.0"), []string{"a/x"}, gemDependency("b/y", "~> 2.0"))
+	graph.addChildVertex("c/x", gemSpec("c/x", "0.1.0"), []string{"b/x"}, gemDependency("c/x", "< 1.0"))
+	return graph
+}
+
+func (s *MySuite) Test_Graph_Export(t *c.C) {
+	out := exportTestGraph().Export(&ExportOptions{})
+	t.Assert(out.Nodes, c.HasLen, 4)
+	t.Assert(out.Nodes[0], c.DeepEquals, &ExportedNode{Name: "a/x", Version: "1.0.0", Root: true, Depth: 1})
+	t.Assert(out.Nodes[3].Depth, c.Equals, 3)
+	t.Assert(out.Edges[0], c.DeepEquals, &ExportedEdge{From: "a/x", To: "b/x", Requirements: []string{">= 2.0"}})
+
+	// Packages collapsed into repositories, limited depth and outdated nodes
+	repo := func(v *Vertex) string { return strings.Split(v.Name, "/")[0] }
+	out = exportTestGraph().Export(&ExportOptions{
+		Depth:  2,
+		Group:  repo,
+		Newest: func(v *Vertex) string { return map[string]string{"b": "3.0.0"}[repo(v)] },
+	})
+	t.Assert(out.Nodes, c.HasLen, 2)
+	t.Assert(out.Nodes[1], c.DeepEquals, &ExportedNode{Name: "b", Version: "2.0.0", Depth: 2, Outdated: true, Newest: "3.0.0"})
+	t.Assert(out.Edges, c.DeepEquals, []*ExportedEdge{{From: "a", To: "b", Requirements: []string{">= 2.0", "~> 2.0"}}})
+
+	// Filtered by name
+	out = exportTestGraph().Export(&ExportOptions{Match: func(name string) bool { return name != "b/y" }})
+	t.Assert(out.Nodes, c.HasLen, 3)
+	t.Assert(out.Edges, c.HasLen, 2)
+}
+
+func (s *MySuite) Test_ExportedGraph_Formats(t *c.C) {
+	out := exportTestGraph().Export(&ExportOptions{Match: func(name string) bool { return name != "c/x" }})
+	out.Nodes[1].Outdated, out.Nodes[1].Newest = true, "3.0.0"
+
+	var dot bytes.Buffer
+	t.Assert(out.WriteDOT(&dot), c.IsNil)
+	t.Assert(dot.String(), c.Equals, `digraph dependencies {
+  node [shape=box];
+  "a/x" [label="a/x\n1.0.0", peripheries=2];
+  "b/x" [label="b/x\n2.0.0 (newest 3.0.0)", style=filled, fillcolor="#ffd7d7"];
+  "b/y" [label="b/y\n2.0.0"];
+  "a/x" -> "b/x" [label=">= 2.0"];
+  "a/x" -> "b/y" [label="~> 2.0"];
+}
+`)
+
+	var mermaid bytes.Buffer
+	t.Assert(out.WriteMermaid(&mermaid), c.IsNil)
+	t.Assert(mermaid.String(), c.Equals, `graph TD
+  n0[["a/x 1.0.0"]]
+  n1["b/x 2.0.0 (newest 3.0.0)"]
+  n2["b/y 2.0.0"]
+  n0 -->|"#gt;= 2.0"| n1
+  n0 -->|"~#gt; 2.0"| n2
+  classDef outdated fill:#ffd7d7,stroke:#cc0000
+  class n1 outdated
+`)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/mdy/melody/resolver/types"
 	"github.com/mdy/melody/version"
+	"strings"
 )
 
 func NewSpec(n, v string) *Specification {
@@ -52,6 +53,16 @@ func (s *Dependency) Name() string {
 	return s.NameStr
 }
 
+// Version range exactly as it was specified
+func (s *Dependency) Constraint() string {
+	return s.RangeStr
+}
+
+// Versions allowed by the range for static checks
+func (s *Dependency) VersionSet() (version.Set, error) {
+	return version.ParseSet(strings.Trim(s.RangeStr, " "), VersionParser)
+}
+
 func (s *Dependency) String() string {
 	return fmt.Sprintf("FlexDependency(%s %s)", s.NameStr, s.RangeStr)
 }
@@ -66,5 +77,10 @@ func (s *Dependency) SatisfiedBy(spec types.Specification) (bool, error) {
 		return false, err
 	}
 
+	// Revisions match pseudo-versions made from them
+	if strings.HasPrefix(s.RangeStr, "#") {
+		return flex.MatchesRevision(s.RangeStr[1:]), nil
+	}
+
 	return version.SatisfiesRange(flex, s.RangeStr)
 }
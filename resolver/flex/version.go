@@ -3,9 +3,11 @@ package flex
 import (
 	"bytes"
 	"github.com/mdy/melody/version"
+	"regexp"
 	"strconv"
 	"strings"
 	tscanner "text/scanner"
+	"time"
 )
 
 // Global version parser
@@ -18,7 +20,31 @@ type Version struct {
 	Build []part
 }
 
+// Go module pseudo-version, like v0.0.0-20190311183353-d8887717615a,
+// v1.2.4-0.20190311183353-d8887717615a or v1.2.4-pre.0.20190311183353-d8887717615a
+var pseudoVersionRE = regexp.MustCompile(`^(\d+\.\d+\.\d+)-(?:([^+]*)\.)?(\d{14})-([0-9A-Za-z]+)(\+.*)?$`)
+
+const pseudoTimeFormat = "20060102150405"
+
 func ParseVersion(src string) (Version, error) {
+	if len(src) > 1 && (src[0] == 'v' || src[0] == 'V') && isDigit(src[1]) {
+		src = src[1:] // Go module tags are prefixed (v1.2.3)
+	}
+
+	// Keep the timestamp and revision of pseudo-versions as single parts,
+	// so they're ordered by their base version and then by timestamp
+	if m := pseudoVersionRE.FindStringSubmatch(src); m != nil {
+		base := m[1] + m[5]
+		if m[2] != "" {
+			base = m[1] + "-" + m[2] + m[5]
+		}
+
+		v, err := ParseVersion(base)
+		timestamp, _ := strconv.ParseUint(m[3], 10, 64)
+		v.Pre = append(v.Pre, part{num: timestamp, isNum: true}, part{str: m[4]})
+		return v, err
+	}
+
 	s := (&tscanner.Scanner{}).Init(strings.NewReader(src))
 	s.Mode = tscanner.ScanIdents | tscanner.ScanInts
 	parts := [][]part{{}, {}, {}}
@@ -111,12 +137,63 @@ func (v Version) MinorBump() version.Version {
 	return Version{main, v.Pre, v.Build}
 }
 
+// Go module pseudo-version that embeds a timestamp and revision
+func (v Version) IsPseudo() bool {
+	if l := len(v.Pre); l >= 2 {
+		ts, rev := v.Pre[l-2], v.Pre[l-1]
+		return ts.isNum && ts.num >= 1e13 && ts.num < 1e14 && !rev.isNum && rev.str != ""
+	}
+	return false
+}
+
+// Revision embedded in a pseudo-version (or "")
+func (v Version) Revision() string {
+	if v.IsPseudo() {
+		return v.Pre[len(v.Pre)-1].str
+	}
+	return ""
+}
+
+// Commit time embedded in a pseudo-version (or zero time)
+func (v Version) Timestamp() time.Time {
+	if v.IsPseudo() {
+		ts := strconv.FormatUint(v.Pre[len(v.Pre)-2].num, 10)
+		t, _ := time.Parse(pseudoTimeFormat, ts)
+		return t
+	}
+	return time.Time{}
+}
+
+// Check if a pseudo-version was made from the revision (either abbreviated)
+func (v Version) MatchesRevision(rev string) bool {
+	own := v.Revision()
+	if own == "" || len(rev) < 7 {
+		return false
+	}
+	return strings.HasPrefix(rev, own) || strings.HasPrefix(own, rev)
+}
+
+// Major version 2+ from a repository without a go.mod (v2.0.0+incompatible)
+func (v Version) IsIncompatible() bool {
+	for _, p := range v.Build {
+		if p.str == "incompatible" {
+			return true
+		}
+	}
+	return false
+}
+
 // Convert to string for debugging. This will likely not be
 // the same as original parsed string (different separators)
 func (v Version) String() string {
 	buffer := bytes.NewBufferString("")
 	stringAppendParts(buffer, "", v.Main)
-	stringAppendParts(buffer, "-", v.Pre)
+	if v.IsPseudo() {
+		stringAppendParts(buffer, "-", v.Pre[:len(v.Pre)-1])
+		buffer.WriteString("-" + v.Revision())
+	} else {
+		stringAppendParts(buffer, "-", v.Pre)
+	}
 	stringAppendParts(buffer, "+", v.Build)
 	return buffer.String()
 }
@@ -136,6 +213,10 @@ func stringAppendParts(buffer *bytes.Buffer, prefix string, parts []part) {
 	}
 }
 
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
 // Version segment (either int or string)
 type part struct {
 	str   string
@@ -3,6 +3,7 @@ package flex
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestVersionParse(t *testing.T) {
@@ -130,6 +131,15 @@ func TestVersionCompare(t *testing.T) {
 		{"1+1", "1+2", 0},
 		{"1.2.3+hi", "1.2.3+9", 0},
 		{"1.2+99", "1.1+999", 2},
+		{"v2.0.0+incompatible", "2.0.0", 0},
+		// Pseudo-versions
+		{"v0.0.0-20190311183353-d8887717615a", "v0.0.0-20190312000000-0123456789ab", -1},
+		{"v0.0.0-20190311183353-d8887717615a", "v0.0.0-20190311183353-d8887717615a", 0},
+		{"v1.2.4-0.20190311183353-d8887717615a", "v1.2.3", 2},
+		{"v1.2.4-0.20190311183353-d8887717615a", "v1.2.4", -1},
+		{"v1.2.4-0.20200101000000-d8887717615a", "v1.2.4-pre.0.20190311183353-d8887717615a", -1},
+		{"v1.2.4-pre.0.20190311183353-d8887717615a", "v1.2.4-pre", 1},
+		{"v1.2.4-pre.0.20190311183353-d8887717615a", "v1.2.4-pre.1", -1},
 	}
 
 	for _, tc := range tests {
@@ -150,3 +160,51 @@ func TestVersionPadding(t *testing.T) {
 		t.Errorf("Compare modified Version by padding")
 	}
 }
+
+func TestVersionPseudo(t *testing.T) {
+	tests := []struct {
+		i, s, rev, ts string
+	}{
+		{"v0.0.0-20190311183353-d8887717615a", "0.0.0-20190311183353-d8887717615a", "d8887717615a", "2019-03-11T18:33:53Z"},
+		{"v1.2.4-0.20190311183353-0123456789ab", "1.2.4-0.20190311183353-0123456789ab", "0123456789ab", "2019-03-11T18:33:53Z"},
+		{"1.2.4-pre.0.20200101000000-d8887717615a+incompatible", "1.2.4-pre.0.20200101000000-d8887717615a+incompatible", "d8887717615a", "2020-01-01T00:00:00Z"},
+		{"v1.2.3", "1.2.3", "", "0001-01-01T00:00:00Z"},
+		{"1.2.3-beta.1", "1.2.3-beta.1", "", "0001-01-01T00:00:00Z"},
+	}
+
+	for _, tc := range tests {
+		v := MustParseVersion(tc.i).(Version)
+		if v.String() != tc.s {
+			t.Errorf("Invalid string for %q: %q", tc.i, v.String())
+		} else if v.IsPseudo() != (tc.rev != "") || v.Revision() != tc.rev {
+			t.Errorf("Invalid revision for %q: %q", tc.i, v.Revision())
+		} else if ts := v.Timestamp().Format(time.RFC3339); ts != tc.ts {
+			t.Errorf("Invalid timestamp for %q: %s", tc.i, ts)
+		}
+	}
+
+	pseudo := MustParseVersion("v0.0.0-20190311183353-d8887717615a").(Version)
+	for rev, ok := range map[string]bool{
+		"d8887717615a": true,
+		"d888771":      true,
+		"d8887717615a1b2c3d4e5f60718293a4b5c6d7e8f9": true,
+		"d88877":       false,
+		"e8887717615a": false,
+	} {
+		if pseudo.MatchesRevision(rev) != ok {
+			t.Errorf("MatchesRevision(%q) should return: %t", rev, ok)
+		}
+	}
+
+	if !MustParseVersion("v2.0.0+incompatible").(Version).IsIncompatible() {
+		t.Errorf("v2.0.0+incompatible should be incompatible")
+	} else if MustParseVersion("v2.0.0").(Version).IsIncompatible() {
+		t.Errorf("v2.0.0 should not be incompatible")
+	}
+
+	// Revision requirements match pseudo-versions
+	dep, spec := NewDependency("lib", "#d888771"), NewSpec("lib", pseudo.String())
+	if ok, err := dep.SatisfiedBy(spec); !ok || err != nil {
+		t.Errorf("%s should be satisfied by %s", dep, spec)
+	}
+}
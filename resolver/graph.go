@@ -2,48 +2,79 @@ package resolver
 
 import (
 	"github.com/gonum/graph"
-	"github.com/gonum/graph/simple"
 	"github.com/mdy/melody/resolver/types"
 	"sort"
 	"strings"
 )
 
-// Graph of dependencies
+// Graph of dependencies.  It's persistent and shares its structure with every
+// duplicate or snapshot, so copies are cheap and only changed paths are copied
 type Graph struct {
-	*simple.DirectedGraph
-	namesToID map[string]int
+	root   *hamtNode
+	size   int
+	nextID int
+	owner  *hamtOwner
+}
+
+// Vertex with its edges as stored in the graph (never changed once shared)
+type graphNode struct {
+	vertex   *Vertex
+	parents  []graphEdge
+	children []string
+	order    int // Topological order of package edges
+}
+
+type graphEdge struct {
+	name        string
+	requirement types.Requirement
 }
 
 func NewGraph() *Graph {
-	simpleGraph := simple.NewDirectedGraph(1, 0)
-	return &Graph{simpleGraph, map[string]int{}}
+	return &Graph{owner: &hamtOwner{}}
 }
 
 func (g *Graph) isEmpty() bool {
-	return len(g.namesToID) == 0
+	return g.size == 0
 }
 
+// Cheap copy that shares all structure with the graph
 func (g *Graph) Dup() *Graph {
-	newGraph := NewGraph()
+	g.owner = &hamtOwner{} // Our nodes are shared from now on
+	return &Graph{root: g.root, size: g.size, nextID: g.nextID, owner: &hamtOwner{}}
+}
 
-	// Dup all nodes by value
-	for _, n := range g.Nodes() {
-		vOld := n.(*Vertex)
-		newGraph.AddNode(vOld.Dup())
-		newGraph.namesToID[vOld.Name] = vOld.ID()
-	}
+// Saved graph that can be restored to undo changes made after it
+type GraphSnapshot struct {
+	root   *hamtNode
+	size   int
+	nextID int
+}
 
-	// Dup all edges with requirement
-	for _, oldSrc := range g.Nodes() {
-		for _, oldDst := range g.From(oldSrc) {
-			newSrc := newGraph.Node(oldSrc.ID()).(*Vertex)
-			newDst := newGraph.Node(oldDst.ID()).(*Vertex)
-			oldReq := g.Edge(oldSrc, oldDst).(*Edge).Requirement
-			newGraph.SetEdge(&Edge{newSrc, newDst, oldReq})
-		}
+func (g *Graph) Snapshot() *GraphSnapshot {
+	g.owner = &hamtOwner{}
+	return &GraphSnapshot{root: g.root, size: g.size, nextID: g.nextID}
+}
+
+func (g *Graph) Restore(s *GraphSnapshot) {
+	g.root, g.size, g.nextID = s.root, s.size, s.nextID
+}
+
+func (g *Graph) node(name string) *graphNode {
+	return g.root.get(hamtHash(name), name, 0)
+}
+
+func (g *Graph) setNode(name string, node *graphNode) {
+	root, added := g.root.set(g.owner, hamtHash(name), name, node, 0)
+	if g.root = root; added {
+		g.size++
 	}
+}
 
-	return newGraph
+func (g *Graph) deleteNode(name string) {
+	root, removed := g.root.delete(g.owner, hamtHash(name), name, 0)
+	if g.root = root; removed {
+		g.size--
+	}
 }
 
 func (g *Graph) DetachNamedVertex(name string) {
@@ -51,38 +82,195 @@ func (g *Graph) DetachNamedVertex(name string) {
 }
 
 func (g *Graph) detachVertexNamed(name string) {
-	if id, ok := g.namesToID[name]; ok {
-		delete(g.namesToID, name)
-		byeNode := g.Node(id)
+	node := g.node(name)
+	if node == nil {
+		return
+	}
+	g.removeNode(name, node)
+
+	// Successors might only be reachable through the detached vertex
+	detached := map[string]bool{}
+	for _, childName := range node.children {
+		g.collectSuccessors(childName, detached)
+	}
 
-		// Save all outgoing nodes
-		outgoing := g.From(byeNode)
+	// Keep successors supported by a root or by a vertex that stays
+	kept := map[string]bool{}
+	for childName := range detached {
+		child := g.node(childName)
+		if child.vertex.Root {
+			g.keepSuccessors(childName, detached, kept)
+			continue
+		}
+		for _, edge := range child.parents {
+			if !detached[edge.name] {
+				g.keepSuccessors(childName, detached, kept)
+				break
+			}
+		}
+	}
+
+	// Remove any loose leafs (or loose cycles)
+	for childName := range detached {
+		if !kept[childName] {
+			g.removeNode(childName, g.node(childName))
+		}
+	}
+}
 
-		// Ditch node/edges
-		g.RemoveNode(byeNode)
+func (g *Graph) collectSuccessors(name string, visited map[string]bool) {
+	if node := g.node(name); node != nil && !visited[name] {
+		visited[name] = true
+		for _, childName := range node.children {
+			g.collectSuccessors(childName, visited)
+		}
+	}
+}
 
-		// Remove any loose leafs
-		for _, n := range outgoing {
-			vertex := n.(*Vertex)
-			if !vertex.Root && len(g.To(n)) == 0 {
-				g.detachVertexNamed(vertex.Name)
+func (g *Graph) keepSuccessors(name string, detached, kept map[string]bool) {
+	if !kept[name] {
+		kept[name] = true
+		for _, childName := range g.node(name).children {
+			if detached[childName] {
+				g.keepSuccessors(childName, detached, kept)
 			}
 		}
 	}
 }
 
+// Remove named vertex while keeping its successors (unlike DetachNamedVertex)
+func (g *Graph) RemoveNamedVertex(name string) {
+	if node := g.node(name); node != nil {
+		g.removeNode(name, node)
+	}
+}
+
+// Ditch node and all of its edges
+func (g *Graph) removeNode(name string, node *graphNode) {
+	for _, edge := range node.parents {
+		parent := *g.node(edge.name)
+		parent.children = withoutName(parent.children, name)
+		g.setNode(edge.name, &parent)
+	}
+
+	for _, childName := range node.children {
+		child := *g.node(childName)
+		child.parents = withoutEdge(child.parents, name)
+		g.setNode(childName, &child)
+	}
+
+	g.deleteNode(name)
+}
+
+// Sorted names of vertices that depend on the named vertex
+func (g *Graph) DependentsOf(name string) []string {
+	names := []string{}
+	if node := g.node(name); node != nil {
+		for _, edge := range node.parents {
+			names = append(names, edge.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sorted names of vertices that the named vertex depends on
+func (g *Graph) DependenciesOf(name string) []string {
+	names := []string{}
+	if node := g.node(name); node != nil {
+		names = append(names, node.children...)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Known requirements on the named vertex, explicit ones first (graphs
+// decoded from Melody.lock have none between vertices)
+func (g *Graph) RequirementsOn(name string) types.Requirements {
+	reqs := types.Requirements{}
+	if node := g.node(name); node != nil {
+		for _, req := range g.requirementsFor(name) {
+			if req != nil {
+				reqs = append(reqs, req)
+			}
+		}
+	}
+	return reqs
+}
+
+// Sorted names of root vertices (explicitly requested)
+func (g *Graph) RootNames() []string {
+	names := []string{}
+	g.root.each(func(node *graphNode) {
+		if node.vertex.Root {
+			names = append(names, node.vertex.Name)
+		}
+	})
+	sort.Strings(names)
+	return names
+}
+
+// Vertex on a dependency path with the requirement that pulled it in, which
+// is the explicit requirement for the root of a path (nil if unknown)
+type PathStep struct {
+	Vertex      *Vertex
+	Requirement types.Requirement
+}
+
+// Every path from a root vertex down to the named vertex.  Paths never visit
+// a vertex twice, so cycles between repositories are only followed once
+func (g *Graph) PathsTo(name string) [][]*PathStep {
+	paths := [][]*PathStep{}
+	onPath := map[string]bool{}
+
+	var walk func(name string, suffix []*PathStep)
+	walk = func(name string, suffix []*PathStep) {
+		node := g.node(name)
+		if node == nil || onPath[name] {
+			return
+		}
+
+		onPath[name] = true
+		defer delete(onPath, name)
+
+		if vertex := node.vertex; vertex.Root {
+			root := &PathStep{Vertex: vertex}
+			if len(vertex.ExplicitRequirements) > 0 {
+				root.Requirement = vertex.ExplicitRequirements[0]
+			}
+			paths = append(paths, append([]*PathStep{root}, suffix...))
+		}
+
+		parents := append([]graphEdge{}, node.parents...)
+		sort.Slice(parents, func(i, j int) bool { return parents[i].name < parents[j].name })
+		for _, edge := range parents {
+			step := &PathStep{node.vertex, edge.requirement}
+			walk(edge.name, append([]*PathStep{step}, suffix...))
+		}
+	}
+
+	walk(name, nil)
+	return paths
+}
+
 func (g *Graph) addVertex(name string, payload types.Specification, root bool) *Vertex {
-	vertex := g.vertexNamed(name)
-	if vertex == nil {
-		vertex = &Vertex{id: g.NewNodeID(), Name: name}
-		g.namesToID[name] = vertex.ID()
-		g.AddNode(vertex)
+	node := g.node(name)
+	if node == nil {
+		g.nextID++
+		vertex := &Vertex{id: g.nextID, Name: name, Payload: payload, Root: root}
+		g.setNode(name, &graphNode{vertex: vertex, order: g.nextID})
+		return vertex
 	}
 
-	if vertex.Payload == nil {
-		vertex.Payload = payload
+	vertex := node.vertex
+	if (vertex.Payload == nil && payload != nil) || (root && !vertex.Root) {
+		vertex = vertex.Dup()
+		if vertex.Payload == nil {
+			vertex.Payload = payload
+		}
+		vertex.Root = vertex.Root || root
+		g.setVertex(node, vertex)
 	}
-	vertex.Root = vertex.Root || root
 	return vertex
 }
 
@@ -90,34 +278,139 @@ func (g *Graph) addChildVertex(name string, payload types.Specification, parents
 	vertex := g.addVertex(name, payload, false)
 	for _, pName := range parents {
 		if pName == "" {
-			vertex.Root = true
+			vertex = g.addVertex(name, nil, true)
 		} else {
-			pNode := g.vertexNamed(pName)
-			if g.hasPath(vertex, pNode) {
-				return nil, &CircularDependencyError{vertex, pNode}
+			if cycle := g.cycleFor(pName, name); cycle != nil {
+				return nil, &CircularDependencyError{vertex, g.vertexNamed(pName), cycle}
 			}
-			g.SetEdge(&Edge{pNode, vertex, req})
+			g.setEdge(pName, name, req)
 		}
 	}
 	return vertex, nil
 }
 
-// Check if path exists between nodes (to prevent circular deps)
-func (g *Graph) hasPath(src graph.Node, dst graph.Node) bool {
-	if src.ID() == dst.ID() {
-		return true
+// Add or replace the edge between two existing vertices
+func (g *Graph) setEdge(from, to string, req types.Requirement) {
+	parent, child := *g.node(from), *g.node(to)
+	if !containsName(parent.children, to) {
+		parent.children = append(withoutName(parent.children, to), to)
+		g.setNode(from, &parent)
 	}
-	for _, n := range g.From(src) {
-		if g.hasPath(n, dst) {
-			return true
+	child.parents = append(withoutEdge(child.parents, from), graphEdge{from, req})
+	g.setNode(to, &child)
+}
+
+// Vertices are shared between copies, so changes are made on a new vertex
+func (g *Graph) setVertex(node *graphNode, vertex *Vertex) {
+	newNode := *node
+	newNode.vertex = vertex
+	g.setNode(vertex.Name, &newNode)
+}
+
+func (g *Graph) setPayload(name string, payload types.Specification) {
+	node := g.node(name)
+	vertex := node.vertex.Dup()
+	vertex.Payload = payload
+	g.setVertex(node, vertex)
+}
+
+func (g *Graph) addExplicitRequirement(name string, req types.Requirement) {
+	node := g.node(name)
+	vertex := node.vertex.Dup()
+	vertex.ExplicitRequirements = append(vertex.ExplicitRequirements.Dup(), req)
+	g.setVertex(node, vertex)
+}
+
+// Releases are named after their repository.  Repositories may depend on
+// each other, so only edges between packages can close a cycle
+func isReleaseName(name string) bool {
+	return strings.HasPrefix(name, "repo://")
+}
+
+// Cycle that a new package edge would close, as names from parent back to
+// parent, or nil.  Vertices keep a topological order of package edges, so an
+// edge that follows the order can't close a cycle.  Otherwise only vertices
+// ordered between both ends are searched and then reordered (Pearce-Kelly)
+func (g *Graph) cycleFor(parent, child string) []string {
+	pNode, cNode := g.node(parent), g.node(child)
+	if pNode == nil || cNode == nil || isReleaseName(parent) || isReleaseName(child) {
+		return nil
+	} else if pNode.order < cNode.order {
+		return nil
+	}
+
+	// Depth-first search from child to parent, remembering how we got there
+	forward, cameFrom := []string{}, map[string]string{child: ""}
+	stack := []string{child}
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		forward = append(forward, name)
+
+		if name == parent {
+			path := []string{}
+			for ; name != ""; name = cameFrom[name] {
+				path = append([]string{name}, path...)
+			}
+			return append([]string{parent}, path...)
+		}
+
+		for _, next := range g.node(name).children {
+			if _, seen := cameFrom[next]; !seen && !isReleaseName(next) && g.node(next).order <= pNode.order {
+				cameFrom[next] = name
+				stack = append(stack, next)
+			}
+		}
+	}
+
+	// Vertices that reach the parent and are ordered after the child
+	backward, seen := []string{}, map[string]bool{parent: true}
+	stack = []string{parent}
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		backward = append(backward, name)
+
+		for _, edge := range g.node(name).parents {
+			if !seen[edge.name] && g.node(edge.name).order >= cNode.order {
+				seen[edge.name] = true
+				stack = append(stack, edge.name)
+			}
+		}
+	}
+
+	g.reorder(backward, forward)
+	return nil
+}
+
+// Move vertices that reach the parent of a new edge before the ones that its
+// child reaches, reusing their positions in the topological order
+func (g *Graph) reorder(backward, forward []string) {
+	byOrder := func(names []string) {
+		sort.Slice(names, func(i, j int) bool { return g.node(names[i]).order < g.node(names[j]).order })
+	}
+	byOrder(backward)
+	byOrder(forward)
+
+	names := append(backward, forward...)
+	orders := make([]int, len(names))
+	for i, name := range names {
+		orders[i] = g.node(name).order
+	}
+	sort.Ints(orders)
+
+	for i, name := range names {
+		if node := g.node(name); node.order != orders[i] {
+			newNode := *node
+			newNode.order = orders[i]
+			g.setNode(name, &newNode)
 		}
 	}
-	return false
 }
 
 func (g *Graph) vertexNamed(name string) *Vertex {
-	if id, ok := g.namesToID[name]; ok {
-		return g.Node(id).(*Vertex)
+	if node := g.node(name); node != nil {
+		return node.vertex
 	}
 	return nil
 }
@@ -137,15 +430,99 @@ func (g *Graph) PayloadFor(name string) types.Specification {
 }
 
 func (g *Graph) requirementsFor(name string) []types.Requirement {
-	vertex := g.vertexNamed(name)
-	requirements := vertex.ExplicitRequirements
-	for _, neighbor := range g.To(vertex) {
-		edge := g.Edge(neighbor, vertex).(*Edge)
-		requirements = append(requirements, edge.Requirement)
+	node := g.node(name)
+	requirements := append([]types.Requirement{}, node.vertex.ExplicitRequirements...)
+	for _, edge := range node.parents {
+		requirements = append(requirements, edge.requirement)
 	}
 	return requirements
 }
 
+// All vertices of the graph
+func (g *Graph) Nodes() []graph.Node {
+	nodes := make([]graph.Node, 0, g.size)
+	g.root.each(func(n *graphNode) {
+		nodes = append(nodes, n.vertex)
+	})
+	return nodes
+}
+
+// Successors of a vertex
+func (g *Graph) From(n graph.Node) []graph.Node {
+	nodes := []graph.Node{}
+	if node := g.node(n.(*Vertex).Name); node != nil {
+		for _, childName := range node.children {
+			nodes = append(nodes, g.vertexNamed(childName))
+		}
+	}
+	return nodes
+}
+
+// Predecessors of a vertex
+func (g *Graph) To(n graph.Node) []graph.Node {
+	nodes := []graph.Node{}
+	if node := g.node(n.(*Vertex).Name); node != nil {
+		for _, edge := range node.parents {
+			nodes = append(nodes, g.vertexNamed(edge.name))
+		}
+	}
+	return nodes
+}
+
+// Edge between two vertices or nil
+func (g *Graph) Edge(u, v graph.Node) graph.Edge {
+	from, to := u.(*Vertex).Name, v.(*Vertex).Name
+	if node := g.node(to); node != nil {
+		for _, edge := range node.parents {
+			if edge.name == from {
+				return &Edge{g.vertexNamed(from), node.vertex, edge.requirement}
+			}
+		}
+	}
+	return nil
+}
+
+// All edges of the graph
+func (g *Graph) Edges() []graph.Edge {
+	edges := []graph.Edge{}
+	g.root.each(func(n *graphNode) {
+		for _, edge := range n.parents {
+			edges = append(edges, &Edge{g.vertexNamed(edge.name), n.vertex, edge.requirement})
+		}
+	})
+	return edges
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Copies of edge lists without the named vertex (never modified in place)
+func withoutName(names []string, name string) []string {
+	out := make([]string, 0, len(names)+1)
+	for _, n := range names {
+		if n != name {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func withoutEdge(edges []graphEdge, name string) []graphEdge {
+	out := make([]graphEdge, 0, len(edges)+1)
+	for _, e := range edges {
+		if e.name != name {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
 // Sorting of Vertices by name
 type verticesByName []graph.Node
 
@@ -157,7 +534,7 @@ func (n verticesByName) Less(i, j int) bool {
 
 // Activated vertices (with payload) vertices by name
 func (g *Graph) ActivatedByName() map[string]types.Specification {
-	active := make(map[string]types.Specification, len(g.namesToID))
+	active := make(map[string]types.Specification, g.size)
 	for _, node := range g.Nodes() {
 		if v := node.(*Vertex); v.Payload != nil {
 			active[v.Name] = v.Payload
@@ -166,6 +543,18 @@ func (g *Graph) ActivatedByName() map[string]types.Specification {
 	return active
 }
 
+// Sorted names of vertices that were never activated
+func (g *Graph) unresolvedNames() []string {
+	names := []string{}
+	for _, node := range g.Nodes() {
+		if v := node.(*Vertex); v.Payload == nil {
+			names = append(names, v.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Return a sorted list of all specifications for testing
 func (g *Graph) Specifications() []types.Specification {
 	nodes := verticesByName(g.Nodes())
@@ -1,8 +1,12 @@
 package resolver
 
 import (
+	"fmt"
 	gnum "github.com/gonum/graph"
+	"github.com/mdy/melody/resolver/types"
 	c "gopkg.in/check.v1"
+	"math/rand"
+	"strings"
 )
 
 func (s *MySuite) Test_Graph_General(t *c.C) {
@@ -21,14 +25,94 @@ func (s *MySuite) Test_Graph_General(t *c.C) {
 	t.Assert(graph.vertexNamed("Child"), c.Equals, child)
 	t.Assert(graph.vertexNamed("Noop"), c.IsNil)
 
-	// Test #Dup (full copy)
+	// Test #Dup (shared until changed)
 	graph2 := graph.Dup()
 	t.Assert(graph2, c.Not(c.Equals), graph)
-	t.Assert(graph2.vertexNamed("Root"), c.Not(c.Equals), root1)
-	t.Assert(graph2.vertexNamed("Root"), c.DeepEquals, root1)
-	t.Assert(graph2.vertexNamed("Child"), c.Not(c.Equals), child)
-	t.Assert(graph2.vertexNamed("Child"), c.DeepEquals, child)
+	t.Assert(graph2.vertexNamed("Root"), c.Equals, root1)
+	t.Assert(graph2.vertexNamed("Child"), c.Equals, child)
 	t.Assert(graph2.vertexNamed("Noop"), c.IsNil)
+
+	// Changes to either copy don't leak into the other
+	graph2.setPayload("Child", gemSpec("Child", "1.0.0"))
+	graph2.addChildVertex("Leaf", nil, []string{"Child"}, nil)
+	graph.detachVertexNamed("Root2")
+	t.Assert(graph.vertexNamed("Child"), c.Equals, child)
+	t.Assert(child.Payload, c.IsNil)
+	t.Assert(graph2.PayloadFor("Child").Version(), c.Equals, "1.0.0")
+	t.Assert(graph.vertexNamed("Leaf"), c.IsNil)
+	t.Assert(graph2.vertexNamed("Root2"), c.NotNil)
+	t.Assert(graph.String(), c.Equals, "Graph(NoSpec(Child) NoSpec(Root))")
+	t.Assert(graph2.String(), c.Equals, "Graph(Spec(Child 1.0.0) NoSpec(Leaf) NoSpec(Root) NoSpec(Root2))")
+}
+
+func (s *MySuite) Test_Graph_SnapshotRestore(t *c.C) {
+	graph := NewGraph()
+	graph.addVertex("root", nil, true)
+	graph.addChildVertex("child", nil, []string{"root"}, nil)
+
+	snapshot := graph.Snapshot()
+	graph.setPayload("child", gemSpec("child", "1.0.0"))
+	graph.addChildVertex("leaf", nil, []string{"child"}, nil)
+	graph.detachVertexNamed("root")
+	t.Assert(graph.isEmpty(), c.Equals, true)
+
+	graph.Restore(snapshot)
+	t.Assert(graph.String(), c.Equals, "Graph(NoSpec(child) NoSpec(root))")
+	t.Assert(graph.DependentsOf("child"), c.DeepEquals, []string{"root"})
+	t.Assert(graph.From(graph.vertexNamed("child")), c.HasLen, 0)
+}
+
+func (s *MySuite) Test_Graph_ManyVertices(t *c.C) {
+	graph := generatedGraph(2000)
+	t.Assert(len(graph.Nodes()), c.Equals, 2000)
+	t.Assert(len(graph.Edges()), c.Equals, 1999)
+
+	for i := 0; i < 2000; i += 2 {
+		graph.RemoveNamedVertex(fmt.Sprintf("pkg-%d", i))
+	}
+	t.Assert(len(graph.Nodes()), c.Equals, 1000)
+	t.Assert(graph.vertexNamed("pkg-1"), c.NotNil)
+	t.Assert(graph.vertexNamed("pkg-2"), c.IsNil)
+}
+
+// Graph where each vertex depends on one of the vertices before it
+func generatedGraph(size int) *Graph {
+	graph := NewGraph()
+	graph.addVertex("pkg-0", gemSpec("pkg-0", "1.0.0"), true)
+	for i := 1; i < size; i++ {
+		name, parent := fmt.Sprintf("pkg-%d", i), fmt.Sprintf("pkg-%d", (i-1)/4)
+		graph.addChildVertex(name, gemSpec(name, "1.0.0"), []string{parent}, nil)
+	}
+	return graph
+}
+
+func (s *MySuite) Benchmark_Graph_Dup(t *c.C) {
+	graph := generatedGraph(1000)
+	t.ResetTimer()
+	for i := 0; i < t.N; i++ {
+		graph.Dup()
+	}
+}
+
+// Resolver pattern: duplicate per step and activate a single vertex
+func (s *MySuite) Benchmark_Graph_DupAndActivate(t *c.C) {
+	graph, spec := generatedGraph(1000), gemSpec("pkg-500", "2.0.0")
+	t.ResetTimer()
+	for i := 0; i < t.N; i++ {
+		graph = graph.Dup()
+		graph.setPayload("pkg-500", spec)
+	}
+}
+
+func (s *MySuite) Benchmark_Graph_SnapshotRestore(t *c.C) {
+	graph, spec := generatedGraph(1000), gemSpec("pkg-500", "2.0.0")
+	t.ResetTimer()
+	for i := 0; i < t.N; i++ {
+		snapshot := graph.Snapshot()
+		graph.setPayload("pkg-500", spec)
+		graph.detachVertexNamed("pkg-10")
+		graph.Restore(snapshot)
+	}
 }
 
 func (s *MySuite) Test_Graph_Circular(t *c.C) {
@@ -42,6 +126,171 @@ func (s *MySuite) Test_Graph_Circular(t *c.C) {
 	t.Assert(err, c.FitsTypeOf, &CircularDependencyError{})
 }
 
+// Add a package and the edge to its release (named after its repository)
+func addPackage(graph *Graph, name string, parents ...string) error {
+	release := "repo://" + name[:strings.Index(name, "/")]
+	if _, err := graph.addChildVertex(name, gemSpec(name, "1.0.0"), parents, nil); err != nil {
+		return err
+	}
+	_, err := graph.addChildVertex(release, gemSpec(release, "1.0.0"), []string{name}, nil)
+	return err
+}
+
+func (s *MySuite) Test_Graph_CircularPath(t *c.C) {
+	graph := NewGraph()
+	t.Assert(addPackage(graph, "a/x", ""), c.IsNil)
+	t.Assert(addPackage(graph, "b/x", "a/x"), c.IsNil)
+	t.Assert(addPackage(graph, "b/y", "a/x"), c.IsNil)
+
+	// Cycles between repositories are allowed
+	t.Assert(addPackage(graph, "a/y", "b/y"), c.IsNil)
+	t.Assert(graph.DependentsOf("repo://a"), c.DeepEquals, []string{"a/x", "a/y"})
+
+	// Package import cycles are reported with their path, across repositories
+	_, err := graph.addChildVertex("a/x", nil, []string{"a/y"}, nil)
+	t.Assert(err, c.FitsTypeOf, &CircularDependencyError{})
+	t.Assert(err.(*CircularDependencyError).Cycle, c.DeepEquals, []string{"a/y", "a/x", "b/y", "a/y"})
+	t.Assert(err, c.ErrorMatches, `CircularDependencyError\(a/x, a/y\): a/y -> a/x -> b/y -> a/y`)
+	t.Assert(graph.DependentsOf("a/x"), c.HasLen, 0)
+
+	// Edges that follow the order are reordered when they don't close a cycle
+	t.Assert(addPackage(graph, "c/x", "b/x"), c.IsNil)
+	_, err = graph.addChildVertex("b/y", nil, []string{"c/x"}, nil)
+	t.Assert(err, c.IsNil)
+	_, err = graph.addChildVertex("c/x", nil, []string{"a/y"}, nil)
+	t.Assert(err, c.FitsTypeOf, &CircularDependencyError{})
+	t.Assert(err.(*CircularDependencyError).Cycle, c.DeepEquals, []string{"a/y", "c/x", "b/y", "a/y"})
+}
+
+// Incremental cycle detection agrees with a full search from the child
+func (s *MySuite) Test_Graph_CircularRandom(t *c.C) {
+	random := rand.New(rand.NewSource(42))
+	graph := NewGraph()
+	for i := 0; i < 50; i++ {
+		graph.addVertex(fmt.Sprintf("pkg-%d", i), nil, true)
+	}
+
+	for i := 0; i < 500; i++ {
+		parent, child := fmt.Sprintf("pkg-%d", random.Intn(50)), fmt.Sprintf("pkg-%d", random.Intn(50))
+		visited := map[string]bool{}
+		graph.collectSuccessors(child, visited)
+
+		_, err := graph.addChildVertex(child, nil, []string{parent}, nil)
+		if visited[parent] {
+			t.Assert(err, c.FitsTypeOf, &CircularDependencyError{})
+		} else {
+			t.Assert(err, c.IsNil)
+		}
+	}
+}
+
+func (s *MySuite) Test_Graph_PathsTo(t *c.C) {
+	graph := NewGraph()
+	graph.addVertex("app", gemSpec("app", "1.0.0"), true)
+	graph.addExplicitRequirement("app", gemDependency("app", "~> 1.0"))
+	graph.addVertex("tool", gemSpec("tool", "2.0.0"), true)
+	graph.addChildVertex("lib", gemSpec("lib", "1.2.0"), []string{"app"}, gemDependency("lib", ">= 1.0"))
+	graph.addChildVertex("lib", nil, []string{"tool"}, gemDependency("lib", "< 2.0"))
+	graph.addChildVertex("dep", gemSpec("dep", "0.1.0"), []string{"lib"}, gemDependency("dep", "0.1.0"))
+
+	describe := func(path []*PathStep) []string {
+		out := []string{}
+		for _, step := range path {
+			req := "-"
+			if step.Requirement != nil {
+				req = step.Requirement.String()
+			}
+			out = append(out, step.Vertex.Name+" "+req)
+		}
+		return out
+	}
+
+	// Every path from a root, with the requirement of each hop
+	paths := graph.PathsTo("dep")
+	t.Assert(paths, c.HasLen, 2)
+	t.Assert(describe(paths[0]), c.DeepEquals, []string{
+		"app " + gemDependency("app", "~> 1.0").String(),
+		"lib " + gemDependency("lib", ">= 1.0").String(),
+		"dep " + gemDependency("dep", "0.1.0").String(),
+	})
+	t.Assert(describe(paths[1])[:2], c.DeepEquals, []string{
+		"tool -", "lib " + gemDependency("lib", "< 2.0").String(),
+	})
+
+	// Roots are a path of their own, unknown names have none
+	t.Assert(graph.PathsTo("app"), c.HasLen, 1)
+	t.Assert(graph.PathsTo("missing"), c.HasLen, 0)
+}
+
+func (s *MySuite) Test_Graph_Neighbours(t *c.C) {
+	graph := NewGraph()
+	graph.addVertex("tool", gemSpec("tool", "2.0.0"), true)
+	graph.addVertex("app", gemSpec("app", "1.0.0"), true)
+	graph.addChildVertex("lib", gemSpec("lib", "1.2.0"), []string{"tool", "app"}, nil)
+	graph.addChildVertex("dep", gemSpec("dep", "0.1.0"), []string{"lib"}, nil)
+	graph.addChildVertex("base", gemSpec("base", "0.2.0"), []string{"lib"}, nil)
+
+	// Names are sorted, and only explicitly requested vertices are roots
+	t.Assert(graph.RootNames(), c.DeepEquals, []string{"app", "tool"})
+	t.Assert(graph.DependenciesOf("lib"), c.DeepEquals, []string{"base", "dep"})
+	t.Assert(graph.DependentsOf("lib"), c.DeepEquals, []string{"app", "tool"})
+	t.Assert(graph.DependenciesOf("dep"), c.HasLen, 0)
+	t.Assert(graph.DependentsOf("app"), c.HasLen, 0)
+
+	// Unknown names have no neighbours
+	t.Assert(graph.DependenciesOf("missing"), c.HasLen, 0)
+	t.Assert(graph.DependentsOf("missing"), c.HasLen, 0)
+
+	// Detached vertices are no longer roots
+	graph.detachVertexNamed("tool")
+	t.Assert(graph.RootNames(), c.DeepEquals, []string{"app"})
+	t.Assert(graph.DependentsOf("lib"), c.DeepEquals, []string{"app"})
+}
+
+func (s *MySuite) Test_Graph_RequirementsOn(t *c.C) {
+	graph := NewGraph()
+	graph.addVertex("app", gemSpec("app", "1.0.0"), true)
+	graph.addExplicitRequirement("app", gemDependency("app", "~> 1.0"))
+	graph.addVertex("tool", gemSpec("tool", "2.0.0"), true)
+	graph.addChildVertex("lib", gemSpec("lib", "1.2.0"), []string{"app"}, gemDependency("lib", ">= 1.0"))
+	graph.addChildVertex("lib", nil, []string{"tool"}, gemDependency("lib", "< 2.0"))
+	graph.addChildVertex("dep", gemSpec("dep", "0.1.0"), []string{"lib"}, nil)
+
+	// Requirements of every dependent, and explicit ones for roots
+	reqs := []string{}
+	for _, req := range graph.RequirementsOn("lib") {
+		reqs = append(reqs, req.String())
+	}
+	t.Assert(reqs, c.DeepEquals, []string{
+		gemDependency("lib", ">= 1.0").String(), gemDependency("lib", "< 2.0").String(),
+	})
+	t.Assert(graph.RequirementsOn("app"), c.DeepEquals, types.Requirements{gemDependency("app", "~> 1.0")})
+
+	// Unknown requirements (like from Melody.lock) are skipped
+	t.Assert(graph.RequirementsOn("dep"), c.HasLen, 0)
+	t.Assert(graph.RequirementsOn("tool"), c.HasLen, 0)
+	t.Assert(graph.RequirementsOn("missing"), c.HasLen, 0)
+}
+
+func (s *MySuite) Test_Graph_CircularDiamonds(t *c.C) {
+	graph := NewGraph()
+	graph.addVertex("pkg-0-0", nil, true)
+	graph.addVertex("pkg-0-1", nil, true)
+
+	// Layers of diamonds have exponentially many paths to the bottom
+	for i := 1; i < 64; i++ {
+		for j := 0; j < 2; j++ {
+			parents := []string{fmt.Sprintf("pkg-%d-0", i-1), fmt.Sprintf("pkg-%d-1", i-1)}
+			_, err := graph.addChildVertex(fmt.Sprintf("pkg-%d-%d", i, j), nil, parents, nil)
+			t.Assert(err, c.IsNil)
+		}
+	}
+
+	_, err := graph.addChildVertex("pkg-0-0", nil, []string{"pkg-63-1"}, nil)
+	t.Assert(err, c.FitsTypeOf, &CircularDependencyError{})
+	t.Assert(err.(*CircularDependencyError).Cycle, c.HasLen, 65)
+}
+
 func (s *MySuite) Test_Graph_Detatch(t *c.C) {
 	var graph *Graph
 	var root, root2, child *Vertex
@@ -81,3 +330,20 @@ func (s *MySuite) Test_Graph_Detatch(t *c.C) {
 	t.Assert(graph.vertexNamed(root.Name), c.Equals, root)
 	t.Assert(len(graph.From(root)), c.Equals, 0)
 }
+
+func (s *MySuite) Test_Graph_RemoveNamedVertex(t *c.C) {
+	graph := NewGraph()
+	graph.addVertex("root", nil, true)
+	graph.addVertex("root2", nil, true)
+	graph.addChildVertex("child", nil, []string{"root", "root2"}, nil)
+	graph.addChildVertex("leaf", nil, []string{"child"}, nil)
+	t.Assert(graph.DependentsOf("child"), c.DeepEquals, []string{"root", "root2"})
+	t.Assert(graph.DependentsOf("noop"), c.DeepEquals, []string{})
+
+	// Successors are kept, unlike detachVertexNamed
+	graph.RemoveNamedVertex("child")
+	t.Assert(graph.vertexNamed("child"), c.IsNil)
+	t.Assert(graph.vertexNamed("leaf"), c.NotNil)
+	t.Assert(graph.DependentsOf("leaf"), c.DeepEquals, []string{})
+	t.Assert(len(graph.From(graph.vertexNamed("root"))), c.Equals, 0)
+}
@@ -0,0 +1,182 @@
+package resolver
+
+import (
+	"hash/fnv"
+	"math/bits"
+)
+
+// Persistent hash array mapped trie from vertex names to graph nodes.  Every
+// update copies only the path to the changed entry, so snapshots can share
+// all untouched nodes.  Nodes created under the current owner are not shared
+// yet and may be edited in place to avoid copying the same path repeatedly
+const (
+	hamtBits  = 5
+	hamtMask  = 1<<hamtBits - 1
+	hamtDepth = 32 // Bits of the hash, collisions are kept in a list below
+)
+
+// Token for in-place edits; must not be zero-sized so each one is unique
+type hamtOwner struct {
+	_ int
+}
+
+type hamtNode struct {
+	owner   *hamtOwner
+	bitmap  uint32
+	entries []hamtEntry
+}
+
+type hamtEntry struct {
+	key   string
+	value *graphNode
+	child *hamtNode
+}
+
+func hamtHash(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+func (n *hamtNode) get(hash uint32, key string, shift uint) *graphNode {
+	for n != nil {
+		if shift >= hamtDepth {
+			for _, e := range n.entries {
+				if e.key == key {
+					return e.value
+				}
+			}
+			return nil
+		}
+
+		bit := uint32(1) << ((hash >> shift) & hamtMask)
+		if n.bitmap&bit == 0 {
+			return nil
+		}
+
+		e := n.entries[bits.OnesCount32(n.bitmap&(bit-1))]
+		if e.child == nil {
+			if e.key == key {
+				return e.value
+			}
+			return nil
+		}
+		n, shift = e.child, shift+hamtBits
+	}
+	return nil
+}
+
+// Node that can be edited in place by the owner
+func (n *hamtNode) editable(owner *hamtOwner) *hamtNode {
+	if n == nil {
+		return &hamtNode{owner: owner}
+	} else if n.owner == owner {
+		return n
+	}
+
+	entries := make([]hamtEntry, len(n.entries), len(n.entries)+1)
+	copy(entries, n.entries)
+	return &hamtNode{owner: owner, bitmap: n.bitmap, entries: entries}
+}
+
+// Set value of key, returns the updated node and whether the key is new
+func (n *hamtNode) set(owner *hamtOwner, hash uint32, key string, value *graphNode, shift uint) (*hamtNode, bool) {
+	node := n.editable(owner)
+	if shift >= hamtDepth {
+		for i, e := range node.entries {
+			if e.key == key {
+				node.entries[i].value = value
+				return node, false
+			}
+		}
+		node.entries = append(node.entries, hamtEntry{key: key, value: value})
+		return node, true
+	}
+
+	bit := uint32(1) << ((hash >> shift) & hamtMask)
+	idx := bits.OnesCount32(node.bitmap & (bit - 1))
+	if node.bitmap&bit == 0 {
+		node.bitmap |= bit
+		node.entries = append(node.entries, hamtEntry{})
+		copy(node.entries[idx+1:], node.entries[idx:])
+		node.entries[idx] = hamtEntry{key: key, value: value}
+		return node, true
+	}
+
+	e := node.entries[idx]
+	if e.child != nil {
+		child, added := e.child.set(owner, hash, key, value, shift+hamtBits)
+		node.entries[idx].child = child
+		return node, added
+	} else if e.key == key {
+		node.entries[idx].value = value
+		return node, false
+	}
+
+	// Push both entries one level down
+	child, _ := (*hamtNode)(nil).set(owner, hamtHash(e.key), e.key, e.value, shift+hamtBits)
+	child, _ = child.set(owner, hash, key, value, shift+hamtBits)
+	node.entries[idx] = hamtEntry{child: child}
+	return node, true
+}
+
+// Delete key, returns the updated node (nil if empty) and whether it existed
+func (n *hamtNode) delete(owner *hamtOwner, hash uint32, key string, shift uint) (*hamtNode, bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	idx := -1
+	if shift >= hamtDepth {
+		for i, e := range n.entries {
+			if e.key == key {
+				idx = i
+			}
+		}
+	} else if bit := uint32(1) << ((hash >> shift) & hamtMask); n.bitmap&bit != 0 {
+		idx = bits.OnesCount32(n.bitmap & (bit - 1))
+	}
+
+	if idx < 0 {
+		return n, false
+	}
+
+	e, node := n.entries[idx], n
+	if e.child != nil {
+		child, removed := e.child.delete(owner, hash, key, shift+hamtBits)
+		if !removed {
+			return n, false
+		} else if child != nil {
+			node = n.editable(owner)
+			node.entries[idx].child = child
+			return node, true
+		}
+	} else if e.key != key {
+		return n, false
+	}
+
+	// Drop the entry itself
+	node = n.editable(owner)
+	if shift < hamtDepth {
+		node.bitmap &^= uint32(1) << ((hash >> shift) & hamtMask)
+	}
+	node.entries = append(node.entries[:idx], node.entries[idx+1:]...)
+	if len(node.entries) == 0 {
+		return nil, true
+	}
+	return node, true
+}
+
+// Visit all values in a stable order
+func (n *hamtNode) each(fn func(*graphNode)) {
+	if n == nil {
+		return
+	}
+	for _, e := range n.entries {
+		if e.child != nil {
+			e.child.each(fn)
+		} else {
+			fn(e.value)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package resolver
+
+import (
+	"fmt"
+	"github.com/mdy/melody/resolver/types"
+	"sort"
+)
+
+// Forced requirements by package name.  An override replaces every other
+// requirement on its package, including the version locked in the base graph
+type Overrides map[string]types.Requirement
+
+func NewOverrides(reqs types.Requirements) Overrides {
+	o := Overrides{}
+	for _, req := range reqs {
+		o[req.Name()] = req
+	}
+	return o
+}
+
+// Requirement to check in place of req
+func (o Overrides) apply(req types.Requirement) types.Requirement {
+	if forced, ok := o[req.Name()]; ok {
+		return forced
+	}
+	return req
+}
+
+// Requirement that was ignored because its package was forced to a version
+type OverriddenRequirement struct {
+	Requirement types.Requirement
+	RequiredBy  string // Empty for explicit requirements
+	Payload     types.Specification
+}
+
+func (o *OverriddenRequirement) String() string {
+	if o.RequiredBy == "" {
+		return fmt.Sprintf("%s is overridden by %s", o.Requirement, o.Payload)
+	}
+	return fmt.Sprintf("%s (required by %s) is overridden by %s", o.Requirement, o.RequiredBy, o.Payload)
+}
+
+// Requirements in the graph that are not met by the forced versions
+func (g *Graph) Overridden(o Overrides, sp SpecificationProvider) []*OverriddenRequirement {
+	out := []*OverriddenRequirement{}
+	names := []string{}
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		vertex := g.vertexNamed(name)
+		if vertex == nil || vertex.Payload == nil {
+			continue
+		}
+
+		for _, req := range vertex.ExplicitRequirements {
+			if !sp.IsRequirementSatisfiedBy(req, g, vertex.Payload) {
+				out = append(out, &OverriddenRequirement{req, "", vertex.Payload})
+			}
+		}
+
+		parents := verticesByName(g.To(vertex))
+		sort.Sort(parents)
+		for _, parent := range parents {
+			req := g.Edge(parent, vertex).(*Edge).Requirement
+			if req != nil && !sp.IsRequirementSatisfiedBy(req, g, vertex.Payload) {
+				parentName := parent.(*Vertex).Name
+				out = append(out, &OverriddenRequirement{req, parentName, vertex.Payload})
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package resolver
+
+import (
+	"github.com/mdy/melody/resolver/rubygem"
+	"github.com/mdy/melody/resolver/types"
+	c "gopkg.in/check.v1"
+)
+
+func (s *MySuite) Test_Resolver_Overrides(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", "~> 1.0"),
+		gemSpec("other", "1.0.0", "lib", ">= 1.1"),
+		gemSpec("lib", "1.0.0"),
+		gemSpec("lib", "1.1.0"),
+		gemSpec("lib", "2.0.0"),
+	)
+
+	requested := types.Requirements{gemDependency("app", ">= 1.0"), gemDependency("other", ">= 1.0")}
+	base := NewGraph()
+	base.addVertex("lib", rubygem.NewSpec("lib", "1.1.0"), false)
+
+	// Forced version ignores both dependents and the locked version
+	resolver := NewResolver(provider, &silentUI{})
+	resolver.Overrides = NewOverrides(types.Requirements{gemDependency("lib", "= 2.0.0")})
+	out, err := resolver.Resolve(requested, base)
+	t.Assert(err, c.IsNil)
+	t.Assert(out.String(), c.Equals, "Graph(Spec(app 1.0.0) Spec(lib 2.0.0) Spec(other 1.0.0))")
+
+	// Graph is only valid with the same overrides
+	t.Assert(out.Validate(requested, provider), c.NotNil)
+	t.Assert(out.ValidateWithOverrides(requested, provider, resolver.Overrides), c.IsNil)
+
+	// Every requirement that was ignored is listed
+	overridden := out.Overridden(resolver.Overrides, provider)
+	t.Assert(overridden, c.HasLen, 1)
+	t.Assert(overridden[0].RequiredBy, c.Equals, "app")
+	t.Assert(overridden[0].Requirement.String(), c.Equals, gemDependency("lib", "~> 1.0").String())
+}
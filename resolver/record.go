@@ -0,0 +1,318 @@
+package resolver
+
+import (
+	"encoding/json"
+	"github.com/mdy/melody/resolver/types"
+	"io"
+	"sort"
+)
+
+// Recorder wraps a SpecificationProvider and captures all of its answers, so
+// that a resolution can be replayed later without network access
+type Recorder struct {
+	SpecificationProvider
+	session *Session
+}
+
+func NewRecorder(provider SpecificationProvider) *Recorder {
+	session := newSession()
+	session.ExplicitSource = provider.NameForExplicitDependencySource()
+	session.LockingSource = provider.NameForLockingDependencySource()
+	return &Recorder{provider, session}
+}
+
+func (r *Recorder) SearchFor(req types.Requirement) []types.Specification {
+	specs := r.SpecificationProvider.SearchFor(req)
+	r.session.Searches[r.session.addRequirement(req)] = r.session.addSpecs(specs)
+	return specs
+}
+
+func (r *Recorder) DependenciesFor(spec types.Specification) types.Requirements {
+	reqs := r.SpecificationProvider.DependenciesFor(spec)
+	r.session.Dependencies[r.session.addSpec(spec)] = r.session.addRequirements(reqs)
+	return reqs
+}
+
+func (r *Recorder) IsRequirementSatisfiedBy(req types.Requirement, g *Graph, spec types.Specification) bool {
+	ok := r.SpecificationProvider.IsRequirementSatisfiedBy(req, g, spec)
+	key := satisfiedKey(r.session.addRequirement(req), r.session.addSpec(spec))
+	r.session.Satisfied[key] = ok
+	return ok
+}
+
+func (r *Recorder) AllowMissing(req types.Requirement) bool {
+	ok := r.SpecificationProvider.AllowMissing(req)
+	r.session.AllowMissing[r.session.addRequirement(req)] = ok
+	return ok
+}
+
+// Remote fetches of the wrapped provider, so that recording keeps the stats
+func (r *Recorder) FetchCount() int {
+	if f, ok := r.SpecificationProvider.(fetchCounter); ok {
+		return f.FetchCount()
+	}
+	return 0
+}
+
+// Snapshot of all recorded answers with the requested requirements and base
+func (r *Recorder) Session(requested types.Requirements, base *Graph) *Session {
+	r.session.Requested = r.session.addRequirements(requested)
+	r.session.Base = []*sessionVertex{}
+	if base == nil {
+		return r.session
+	}
+
+	nodes := verticesByName(base.Nodes())
+	sort.Sort(nodes)
+	for _, node := range nodes {
+		vertex := node.(*Vertex)
+		sVertex := &sessionVertex{Name: vertex.Name, Root: vertex.Root}
+		if vertex.Payload != nil {
+			sVertex.Spec = r.session.addSpec(vertex.Payload)
+		}
+
+		for _, parent := range base.To(vertex) {
+			sVertex.Parents = append(sVertex.Parents, parent.(*Vertex).Name)
+		}
+
+		sort.Strings(sVertex.Parents)
+		r.session.Base = append(r.session.Base, sVertex)
+	}
+
+	return r.session
+}
+
+// Recorded resolution session that can be saved as JSON
+type Session struct {
+	Strategy       string                         `json:"strategy,omitempty"`
+	Requested      []string                       `json:"requested"`
+	Overrides      []string                       `json:"overrides,omitempty"`
+	Base           []*sessionVertex               `json:"base"`
+	ExplicitSource string                         `json:"explicitSource"`
+	LockingSource  string                         `json:"lockingSource"`
+	Requirements   map[string]*sessionRequirement `json:"requirements"`
+	Specs          map[string]*sessionSpec        `json:"specs"`
+	Searches       map[string][]string            `json:"searches"`
+	Dependencies   map[string][]string            `json:"dependencies"`
+	Satisfied      map[string]bool                `json:"satisfied"`
+	AllowMissing   map[string]bool                `json:"allowMissing"`
+}
+
+func newSession() *Session {
+	return &Session{
+		Requirements: map[string]*sessionRequirement{},
+		Specs:        map[string]*sessionSpec{},
+		Searches:     map[string][]string{},
+		Dependencies: map[string][]string{},
+		Satisfied:    map[string]bool{},
+		AllowMissing: map[string]bool{},
+	}
+}
+
+// Read a session that was previously written by Encode
+func DecodeSession(r io.Reader) (*Session, error) {
+	session := newSession()
+	if err := json.NewDecoder(r).Decode(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *Session) Encode(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+type sessionRequirement struct {
+	Name string `json:"name"`
+}
+
+type sessionSpec struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Revision string `json:"revision,omitempty"`
+	String   string `json:"string"`
+}
+
+type sessionVertex struct {
+	Name    string   `json:"name"`
+	Spec    string   `json:"spec,omitempty"`
+	Root    bool     `json:"root,omitempty"`
+	Parents []string `json:"parents,omitempty"`
+}
+
+// Requirements are identified by their description
+func (s *Session) addRequirement(req types.Requirement) string {
+	key := req.String()
+	if _, ok := s.Requirements[key]; !ok {
+		s.Requirements[key] = &sessionRequirement{Name: req.Name()}
+	}
+	return key
+}
+
+func (s *Session) addRequirements(reqs types.Requirements) []string {
+	keys := []string{}
+	for _, req := range reqs {
+		keys = append(keys, s.addRequirement(req))
+	}
+	return keys
+}
+
+// Specifications are identified by their name, version and revision
+// (revisions of the same version are different specs)
+func (s *Session) addSpec(spec types.Specification) string {
+	key := specKey(spec)
+	if _, ok := s.Specs[key]; !ok {
+		s.Specs[key] = &sessionSpec{spec.Name(), spec.Version(), revisionOfSpec(spec), spec.String()}
+	}
+	return key
+}
+
+func (s *Session) addSpecs(specs []types.Specification) []string {
+	keys := []string{}
+	for _, spec := range specs {
+		keys = append(keys, s.addSpec(spec))
+	}
+	return keys
+}
+
+func specKey(spec types.Specification) string {
+	key := spec.Name() + " " + spec.Version()
+	if rev := revisionOfSpec(spec); rev != "" {
+		key += "#" + rev
+	}
+	return key
+}
+
+func revisionOfSpec(spec types.Specification) string {
+	if r, ok := spec.(revisioned); ok {
+		return r.Revision()
+	}
+	return ""
+}
+
+func satisfiedKey(reqKey, specKey string) string {
+	return reqKey + " => " + specKey
+}
+
+///////////////////////////////////////////////////
+// Replaying recorded sessions
+///////////////////////////////////////////////////
+
+// Provider that answers from the recorded session
+func (s *Session) Provider() SpecificationProvider {
+	return &replayProvider{session: s}
+}
+
+// Requested requirements as they were recorded
+func (s *Session) RequestedRequirements() types.Requirements {
+	return s.requirements(s.Requested)
+}
+
+// Record forced requirements, so that a replay resolves the same way
+func (s *Session) SetOverrides(o Overrides) {
+	reqs := types.Requirements{}
+	for _, req := range o {
+		reqs = append(reqs, req)
+	}
+	s.Overrides = s.addRequirements(reqs)
+	sort.Strings(s.Overrides)
+}
+
+// Forced requirements as they were recorded
+func (s *Session) RecordedOverrides() Overrides {
+	return NewOverrides(s.requirements(s.Overrides))
+}
+
+// Base graph as it was recorded
+func (s *Session) BaseGraph() *Graph {
+	graph := NewGraph()
+	for _, v := range s.Base {
+		graph.addVertex(v.Name, s.spec(v.Spec), v.Root)
+	}
+	for _, v := range s.Base {
+		graph.addChildVertex(v.Name, nil, v.Parents, nil)
+	}
+	return graph
+}
+
+func (s *Session) requirements(keys []string) types.Requirements {
+	reqs := types.Requirements{}
+	for _, key := range keys {
+		if sReq, ok := s.Requirements[key]; ok {
+			reqs = append(reqs, &replayRequirement{key, sReq.Name, s})
+		}
+	}
+	return reqs
+}
+
+func (s *Session) spec(key string) types.Specification {
+	if sSpec, ok := s.Specs[key]; ok {
+		return &replaySpec{key, sSpec, s}
+	}
+	return nil
+}
+
+type replayProvider struct {
+	BaseProvider
+	session *Session
+}
+
+func (p *replayProvider) SearchFor(req types.Requirement) []types.Specification {
+	specs := []types.Specification{}
+	for _, key := range p.session.Searches[req.String()] {
+		specs = append(specs, p.session.spec(key))
+	}
+	return specs
+}
+
+func (p *replayProvider) DependenciesFor(spec types.Specification) types.Requirements {
+	return p.session.requirements(p.session.Dependencies[specKey(spec)])
+}
+
+func (p *replayProvider) IsRequirementSatisfiedBy(req types.Requirement, _ *Graph, spec types.Specification) bool {
+	return p.session.Satisfied[satisfiedKey(req.String(), specKey(spec))]
+}
+
+func (p *replayProvider) AllowMissing(req types.Requirement) bool {
+	return p.session.AllowMissing[req.String()]
+}
+
+func (p *replayProvider) NameForExplicitDependencySource() string {
+	return p.session.ExplicitSource
+}
+
+func (p *replayProvider) NameForLockingDependencySource() string {
+	return p.session.LockingSource
+}
+
+// Recorded specification
+type replaySpec struct {
+	key     string
+	spec    *sessionSpec
+	session *Session
+}
+
+func (s *replaySpec) Name() string     { return s.spec.Name }
+func (s *replaySpec) Version() string  { return s.spec.Version }
+func (s *replaySpec) Revision() string { return s.spec.Revision }
+func (s *replaySpec) String() string   { return s.spec.String }
+
+func (s *replaySpec) Requirements() types.Requirements {
+	return s.session.requirements(s.session.Dependencies[s.key])
+}
+
+// Recorded requirement
+type replayRequirement struct {
+	key     string
+	name    string
+	session *Session
+}
+
+func (r *replayRequirement) Name() string   { return r.name }
+func (r *replayRequirement) String() string { return r.key }
+
+func (r *replayRequirement) SatisfiedBy(spec types.Specification) (bool, error) {
+	return r.session.Satisfied[satisfiedKey(r.key, specKey(spec))], nil
+}
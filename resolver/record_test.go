@@ -0,0 +1,164 @@
+package resolver
+
+import (
+	"bytes"
+	"github.com/mdy/melody/resolver/rubygem"
+	"github.com/mdy/melody/resolver/types"
+	c "gopkg.in/check.v1"
+)
+
+func (s *MySuite) Test_Recorder_Replay(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", "< 2.0"),
+		gemSpec("app", "1.4.0", "lib", ">= 2.0"),
+		gemSpec("other", "1.0.0", "lib", ">= 1.0"),
+		gemSpec("lib", "1.0.0"),
+		gemSpec("lib", "2.0.0"),
+	)
+
+	requested := types.Requirements{gemDependency("app", "~> 1.0"), gemDependency("other", "~> 1.0")}
+	base := NewGraph()
+	base.addVertex("app", gemSpec("app", "1.0.0"), true)
+	base.addChildVertex("lib", gemSpec("lib", "1.0.0"), []string{"app"}, nil)
+
+	// Record the original resolution
+	recorder := NewRecorder(provider)
+	resolver := NewResolver(recorder, &silentUI{})
+	expected, err := resolver.Resolve(requested, base)
+	t.Assert(err, c.IsNil)
+
+	var buffer bytes.Buffer
+	t.Assert(recorder.Session(requested, base).Encode(&buffer), c.IsNil)
+
+	// Replay it from the JSON snapshot only
+	session, err := DecodeSession(&buffer)
+	t.Assert(err, c.IsNil)
+	t.Assert(session.BaseGraph().String(), c.Equals, base.String())
+
+	replay := NewResolver(session.Provider(), &silentUI{})
+	actual, err := replay.Resolve(session.RequestedRequirements(), session.BaseGraph())
+	t.Assert(err, c.IsNil)
+	t.Assert(actual.String(), c.Equals, expected.String())
+	t.Assert(actual.String(), c.Equals, "Graph(Spec(app 1.0.0) Spec(lib 1.0.0) Spec(other 1.0.0))")
+}
+
+// Requirement for a revision, whatever its version
+type revisionDependency struct {
+	name, revision string
+}
+
+func (d *revisionDependency) Name() string   { return d.name }
+func (d *revisionDependency) String() string { return d.name + " #" + d.revision }
+
+func (d *revisionDependency) SatisfiedBy(spec types.Specification) (bool, error) {
+	r, ok := spec.(revisioned)
+	return ok && spec.Name() == d.name && r.Revision() == d.revision, nil
+}
+
+// Provider searching specs in the order they're listed
+type listProvider struct {
+	BaseProvider
+	specs []types.Specification
+}
+
+func (p *listProvider) SearchFor(dep types.Requirement) []types.Specification {
+	specs := []types.Specification{}
+	for _, s := range p.specs {
+		if p.IsRequirementSatisfiedBy(dep, nil, s) {
+			specs = append(specs, s)
+		}
+	}
+	return specs
+}
+
+func (p *listProvider) DependenciesFor(spec types.Specification) types.Requirements {
+	return spec.Requirements()
+}
+
+func (p *listProvider) IsRequirementSatisfiedBy(d types.Requirement, _ *Graph, spec types.Specification) bool {
+	ok, _ := d.SatisfiedBy(spec)
+	return ok
+}
+
+func (s *MySuite) Test_Recorder_ReplayRevisions(t *c.C) {
+	app := gemSpec("app", "1.0.0")
+	app.Dependencies = rubygem.Requirements{&revisionDependency{"lib", "aaa"}}
+	provider := &listProvider{specs: []types.Specification{
+		app,
+		&revisionedGem{gemSpec("lib", "1.0.0"), "bbb"},
+		&revisionedGem{gemSpec("lib", "1.0.0"), "aaa"},
+	}}
+	requested := types.Requirements{gemDependency("app", "~> 1.0"), gemDependency("lib", ">= 1.0")}
+
+	recorder := NewRecorder(provider)
+	resolver := NewResolver(recorder, &silentUI{})
+	expected, err := resolver.Resolve(requested, nil)
+	t.Assert(err, c.IsNil)
+
+	var buffer bytes.Buffer
+	session := recorder.Session(requested, nil)
+	t.Assert(session.Specs, c.HasLen, 3)
+	t.Assert(session.Encode(&buffer), c.IsNil)
+
+	// Both revisions of lib 1.0.0 are replayed as they were recorded
+	session, err = DecodeSession(&buffer)
+	t.Assert(err, c.IsNil)
+
+	replay := NewResolver(session.Provider(), &silentUI{})
+	actual, err := replay.Resolve(session.RequestedRequirements(), nil)
+	t.Assert(err, c.IsNil)
+	t.Assert(actual.String(), c.Equals, expected.String())
+	t.Assert(actual.PayloadFor("lib").(revisioned).Revision(), c.Equals, "aaa")
+}
+
+// Provider that counts searches as remote fetches
+type fetchingProvider struct {
+	*testSpecProvider
+	fetches int
+}
+
+func (p *fetchingProvider) SearchFor(dep types.Requirement) []types.Specification {
+	p.fetches++
+	return p.testSpecProvider.SearchFor(dep)
+}
+
+func (p *fetchingProvider) FetchCount() int {
+	return p.fetches
+}
+
+// Stats of the last resolution
+type statsEvents struct {
+	BaseEvents
+	stats *Stats
+}
+
+func (e *statsEvents) OnComplete(_ *Graph, stats *Stats) {
+	e.stats = stats
+}
+
+func (s *MySuite) Test_Recorder_FetchCount(t *c.C) {
+	requested := types.Requirements{gemDependency("app", "~> 1.0")}
+	fetches := []int{}
+	for _, record := range []bool{false, true} {
+		provider := &fetchingProvider{testSpecProvider: gemIndex(
+			gemSpec("app", "1.0.0", "lib", ">= 1.0"),
+			gemSpec("lib", "1.0.0"),
+		)}
+
+		var sp SpecificationProvider = provider
+		if record {
+			sp = NewRecorder(provider)
+		}
+
+		events := &statsEvents{}
+		resolver := NewResolver(sp, &silentUI{})
+		resolver.Events = events
+		_, err := resolver.Resolve(requested, nil)
+		t.Assert(err, c.IsNil)
+		fetches = append(fetches, events.stats.Fetches)
+	}
+
+	// Recording doesn't hide the fetches of the recorded provider
+	t.Assert(fetches[0], c.Not(c.Equals), 0)
+	t.Assert(fetches[1], c.Equals, fetches[0])
+}
@@ -0,0 +1,144 @@
+package resolver
+
+import (
+	"github.com/mdy/melody/resolver/types"
+	"github.com/mdy/melody/version"
+	"sort"
+)
+
+// Structured report of a single conflicting dependency
+type ConflictReport struct {
+	Name         string               `json:"name"`
+	Requirements []*RequirementReport `json:"requirements"`
+	Locked       string               `json:"locked,omitempty"`
+	Candidates   []string             `json:"candidates"`
+	Suggestions  []string             `json:"suggestions,omitempty"`
+
+	// Constraints that no version can satisfy together
+	Unsatisfiable []string `json:"unsatisfiable,omitempty"`
+}
+
+// Conflicting requirement and the chain of dependents that required it
+type RequirementReport struct {
+	Constraint string             `json:"constraint"`
+	Explicit   bool               `json:"explicit"`
+	RequiredBy []*DependentReport `json:"requiredBy"`
+}
+
+// Dependent in a requirement chain (from the project root down)
+type DependentReport struct {
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	Constraint string `json:"constraint"`
+}
+
+// Constraint of a requirement or its description as a fallback
+func constraintFor(req types.Requirement) string {
+	if c, ok := req.(ConstrainedRequirement); ok {
+		return c.Constraint()
+	}
+	return req.String()
+}
+
+// Requirements that can tell their original version constraint
+type ConstrainedRequirement interface {
+	Constraint() string
+}
+
+// Requirements that can tell which versions they allow
+type RangedRequirement interface {
+	VersionSet() (version.Set, error)
+}
+
+// Smallest group of constraints that no version can satisfy together, which
+// is usually a pair like ">=1.4" and "<1.2", or nil if they can be satisfied
+func unsatisfiableConstraints(reqs []types.Requirement) []string {
+	sets, constraints, seen := []version.Set{}, []string{}, map[string]bool{}
+	for _, req := range reqs {
+		r, ok := req.(RangedRequirement)
+		if !ok || seen[constraintFor(req)] {
+			continue
+		} else if set, err := r.VersionSet(); err == nil {
+			seen[constraintFor(req)] = true
+			sets, constraints = append(sets, set), append(constraints, constraintFor(req))
+		}
+	}
+
+	for i, set := range sets {
+		if set.IsEmpty() {
+			return constraints[i : i+1]
+		}
+	}
+
+	for i := range sets {
+		for j := i + 1; j < len(sets); j++ {
+			if sets[i].Intersect(sets[j]).IsEmpty() {
+				return []string{constraints[i], constraints[j]}
+			}
+		}
+	}
+
+	all := version.Set{version.Interval{}}
+	for _, set := range sets {
+		all = all.Intersect(set)
+	}
+	if len(sets) > 0 && all.IsEmpty() {
+		return constraints
+	}
+	return nil
+}
+
+// Structured representation of all conflicts sorted by name
+func (e *VersionConflictError) Report() []*ConflictReport {
+	reports := []*ConflictReport{}
+	for name, c := range Conflicts(*e) {
+		reports = append(reports, c.report(name))
+	}
+
+	sort.Sort(conflictReportSort(reports))
+	return reports
+}
+
+func (c *Conflict) report(name string) *ConflictReport {
+	report := &ConflictReport{Name: name, Suggestions: c.Suggestions, Unsatisfiable: c.Unsatisfiable}
+	report.Requirements = []*RequirementReport{}
+	report.Candidates = []string{}
+
+	if l, ok := c.LockedRequirement.(*lockedRequirement); ok {
+		report.Locked = l.Version()
+	}
+
+	for _, s := range c.Candidates {
+		report.Candidates = append(report.Candidates, s.Version())
+	}
+
+	for _, branch := range c.RequirementTrees {
+		if len(branch) == 0 {
+			continue
+		}
+
+		last := branch[len(branch)-1]
+		rReport := &RequirementReport{Constraint: constraintFor(last)}
+		rReport.RequiredBy = []*DependentReport{}
+		rReport.Explicit = len(branch) == 1
+
+		for _, r := range branch[:len(branch)-1] {
+			dReport := &DependentReport{Name: r.Name(), Constraint: constraintFor(r)}
+			if spec, ok := c.ActivatedByName[r.Name()]; ok {
+				dReport.Version = spec.Version()
+			}
+			rReport.RequiredBy = append(rReport.RequiredBy, dReport)
+		}
+
+		report.Requirements = append(report.Requirements, rReport)
+	}
+
+	return report
+}
+
+// Sorting conflict reports by name
+type conflictReportSort []*ConflictReport
+
+func (s conflictReportSort) Len() int           { return len(s) }
+func (s conflictReportSort) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s conflictReportSort) Less(i, j int) bool { return s[i].Name < s[j].Name }
@@ -0,0 +1,82 @@
+package resolver
+
+import (
+	"github.com/mdy/melody/resolver/rubygem"
+	"github.com/mdy/melody/resolver/types"
+	c "gopkg.in/check.v1"
+)
+
+func (s *MySuite) Test_VersionConflictError_Report(t *c.C) {
+	appReq, libReq := gemDependency("app", "~> 1.0"), gemDependency("lib", ">= 2.0")
+	explicitReq := gemDependency("lib", "< 2.0")
+
+	err := VersionConflictError(Conflicts{
+		"lib": &Conflict{
+			Requirement:       libReq,
+			LockedRequirement: &lockedRequirement{rubygem.NewSpec("lib", "1.5.0")},
+			RequirementTrees:  [][]types.Requirement{{appReq, libReq}, {explicitReq}},
+			ActivatedByName: map[string]types.Specification{
+				"app": rubygem.NewSpec("app", "1.2.0"),
+			},
+			Candidates: []types.Specification{
+				rubygem.NewSpec("lib", "2.0.0"),
+				rubygem.NewSpec("lib", "2.1.0"),
+			},
+		},
+	})
+
+	t.Assert(err.Report(), c.DeepEquals, []*ConflictReport{{
+		Name:   "lib",
+		Locked: "1.5.0",
+		Requirements: []*RequirementReport{{
+			Constraint: ">= 2.0",
+			RequiredBy: []*DependentReport{
+				{Name: "app", Version: "1.2.0", Constraint: "~> 1.0"},
+			},
+		}, {
+			Constraint: "< 2.0",
+			Explicit:   true,
+			RequiredBy: []*DependentReport{},
+		}},
+		Candidates: []string{"2.0.0", "2.1.0"},
+	}})
+}
+
+func (s *MySuite) Test_Resolver_Unsatisfiable(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", ">= 1.4"),
+		gemSpec("lib", "1.0.0"),
+		gemSpec("lib", "1.5.0"),
+	)
+
+	requested := types.Requirements{gemDependency("lib", "< 1.2"), gemDependency("app", "~> 1.0")}
+	_, err := NewResolver(provider, &silentUI{}).Resolve(requested, nil)
+	t.Assert(err, c.FitsTypeOf, &VersionConflictError{})
+
+	report := err.(*VersionConflictError).Report()
+	t.Assert(report, c.HasLen, 1)
+	t.Assert(report[0].Unsatisfiable, c.DeepEquals, []string{"< 1.2", ">= 1.4"})
+	t.Assert(report[0].Candidates, c.DeepEquals, []string{"1.5.0"})
+	t.Assert(err, c.ErrorMatches, `(?s).*no version satisfies < 1\.2 >= 1\.4.*`)
+
+	// Conflicts that some version could satisfy
+	t.Assert(unsatisfiableConstraints(types.Requirements{
+		gemDependency("lib", ">= 1.0"), gemDependency("lib", "< 1.2"),
+	}), c.IsNil)
+}
+
+func (s *MySuite) Test_Resolver_ConflictCandidates(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", ">= 1.4"),
+		gemSpec("lib", "1.0.0"),
+		gemSpec("lib", "1.5.0"),
+		gemSpec("lib", "1.6.0"),
+	)
+
+	// Candidates are the possibilities considered, sorted by version
+	requested := types.Requirements{gemDependency("lib", "< 1.2"), gemDependency("app", "~> 1.0")}
+	resolver := &Resolver{provider: provider, ui: &silentUI{}, Strategy: MinimalStrategy}
+	_, err := resolver.Resolve(requested, nil)
+	t.Assert(err, c.FitsTypeOf, &VersionConflictError{})
+	t.Assert(err.(*VersionConflictError).Report()[0].Candidates, c.DeepEquals, []string{"1.5.0", "1.6.0"})
+}
@@ -8,6 +8,15 @@ import (
 type Resolver struct {
 	provider SpecificationProvider
 	ui       UI
+
+	// Preference between possible versions
+	Strategy Strategy
+
+	// Optional hooks to follow the resolution
+	Events Events
+
+	// Forced requirements that replace any other on their package
+	Overrides Overrides
 }
 
 func NewResolver(provider SpecificationProvider, ui UI) *Resolver {
@@ -23,6 +32,9 @@ func (r *Resolver) Resolve(requested []types.Requirement, base *Graph) (*Graph,
 		OriginalRequested: requested,
 		Base:              base,
 		UI:                r.ui,
+		Strategy:          r.Strategy,
+		Events:            r.Events,
+		Overrides:         r.Overrides,
 	}).Resolve()
 }
 
@@ -39,15 +51,37 @@ type Resolution struct {
 	// Explicitly requested updates
 	OriginalRequested []types.Requirement
 
+	// Preference between possible versions
+	Strategy Strategy
+
+	// Hooks to follow the resolution (optional)
+	Events Events
+
+	// Forced requirements by package name (optional)
+	Overrides Overrides
+
 	// Internal processing
 	iterationCounter int
 	progressAt       time.Time
 	startedAt        time.Time
 	endedAt          time.Time
 	states           []*State
+	stats            Stats
 }
 
 func (r *Resolution) Resolve() (*Graph, error) {
+	graph, err := r.resolve()
+	if r.Events != nil {
+		completed := graph
+		if err != nil {
+			completed = nil
+		}
+		r.Events.OnComplete(completed, r.currentStats())
+	}
+	return graph, err
+}
+
+func (r *Resolution) resolve() (*Graph, error) {
 	r.startResolution()
 	defer r.endResolution()
 
@@ -76,12 +110,49 @@ func (r *Resolution) Resolve() (*Graph, error) {
 		}
 	}
 
-	if len(r.state().Conflicts) == 0 {
-		return r.state().Activated, nil
+	return r.resolvedGraph()
+}
+
+// Final graph or an error if anything was left unresolved
+func (r *Resolution) resolvedGraph() (*Graph, error) {
+	state := r.state()
+
+	// Conflicts that are still not met by the activated graph
+	unresolved := Conflicts{}
+	for name, conflict := range state.Conflicts {
+		if !r.isVertexSatisfied(state.Activated, name) {
+			unresolved[name] = conflict
+		}
+	}
+
+	if len(unresolved) > 0 {
+		err := VersionConflictError(unresolved)
+		return NewGraph(), &err
 	}
 
-	//err := fmt.Errorf("Unexpected conflicts: %s", r.state().Conflicts)
-	return r.state().Activated, nil
+	// Every vertex should have a specification by now
+	if names := state.Activated.unresolvedNames(); len(names) > 0 {
+		return NewGraph(), &UnresolvedError{Names: names}
+	}
+
+	return state.Activated, nil
+}
+
+// Check that named vertex is activated and meets all its requirements
+func (r *Resolution) isVertexSatisfied(graph *Graph, name string) bool {
+	vertex := graph.vertexNamed(name)
+	if vertex == nil {
+		return true // Pruned from the graph
+	} else if vertex.Payload == nil {
+		return false
+	}
+
+	for _, req := range graph.requirementsFor(name) {
+		if req != nil && !r.isRequirementSatisfiedBy(req, graph, vertex.Payload) {
+			return false
+		}
+	}
+	return true
 }
 
 func (r *Resolution) startResolution() {
@@ -94,7 +165,7 @@ func (r *Resolution) startResolution() {
 
 func (r *Resolution) endResolution() {
 	r.endedAt = time.Now()
-	r.UI.AfterResolution()
+	r.UI.AfterResolution(r.currentStats())
 	r.debug("Finished resolution (%d steps in %s)",
 		r.iterationCounter, r.endedAt.Sub(r.startedAt))
 }
@@ -134,8 +205,8 @@ func (r *Resolution) state() *State {
 func (r *Resolution) initialState() *State {
 	graph := NewGraph()
 	for _, dep := range r.OriginalRequested {
-		vertex := graph.addVertex(dep.Name(), nil, true)
-		vertex.ExplicitRequirements = append(vertex.ExplicitRequirements, dep)
+		graph.addVertex(dep.Name(), nil, true)
+		graph.addExplicitRequirement(dep.Name(), dep)
 	}
 
 	state := &State{
@@ -151,7 +222,8 @@ func (r *Resolution) initialState() *State {
 		state.Possibilities = []types.Specification{}
 	} else {
 		initialRequirement, requirements := requirements[0], requirements[1:]
-		state.Possibilities = r.SpecProvider.SearchFor(initialRequirement)
+		state.Possibilities = r.searchFor(initialRequirement)
+		state.Candidates = state.Possibilities
 		state.Name = initialRequirement.Name()
 		state.Requirement = initialRequirement
 		state.Requirements = requirements
@@ -162,12 +234,21 @@ func (r *Resolution) initialState() *State {
 
 func (r *Resolution) unwindForConflict() error {
 	r.debug("Unwinding for conflict: %s", r.state().Requirement)
+	r.stats.Backtracks++
 	conflicts := r.state().Conflicts
 
-	i := r.stateIndexForUnwind()
+	i, fromDepth := r.stateIndexForUnwind(), r.state().Depth
 	r.debug("stateIndexForUnwind: %d of %d", i, len(r.states))
 	r.states = r.states[:i+1]
 
+	if r.Events != nil {
+		toDepth := -1
+		if len(r.states) > 0 {
+			toDepth = r.state().Depth
+		}
+		r.Events.OnBacktrack(fromDepth, toDepth)
+	}
+
 	if len(r.states) == 0 {
 		err := VersionConflictError(conflicts)
 		return &err
@@ -254,20 +335,35 @@ func (r *Resolution) createConflict() {
 		requirements[key] = []types.Requirement{lockedReq}
 	}
 
-	state.Conflicts[state.Name] = &Conflict{
+	conflict := &Conflict{
 		LockedRequirement: lockedReq,
 		Requirements:      requirements,
 		Requirement:       state.Requirement,
 		Existing:          r.possibility(),
 		RequirementTrees:  r.requirementTrees(),
 		ActivatedByName:   state.Activated.ActivatedByName(),
+		Candidates:        r.candidates(state),
+	}
+
+	// Requirements that can never be met at the same time
+	conflicting := append([]types.Requirement{}, vertex.ExplicitRequirements...)
+	for _, tree := range conflict.RequirementTrees {
+		if len(tree) > 0 {
+			conflicting = append(conflicting, tree[len(tree)-1])
+		}
+	}
+	conflict.Unsatisfiable = unsatisfiableConstraints(conflicting)
+
+	state.Conflicts[state.Name] = conflict
+	if r.Events != nil {
+		r.Events.OnConflict(state.Name, conflict)
 	}
 }
 
 func (r *Resolution) requirementTrees() [][]types.Requirement {
 	state := r.state()
 	requirements := state.Activated.requirementsFor(state.Name)
-	out := make([][]types.Requirement, len(requirements))
+	out := make([][]types.Requirement, 0, len(requirements))
 	for _, req := range requirements {
 		tree := []types.Requirement{} // requirementTreeFor
 		for ; req != nil; req = r.parentOf(req) {
@@ -282,11 +378,29 @@ func (r *Resolution) indicateProgress() {
 	now := time.Now()
 	r.iterationCounter++
 	if now.Sub(r.progressAt) >= r.UI.ProgressRate() {
-		r.UI.IndicateProgress()
+		r.UI.IndicateProgress(r.currentStats())
 		r.progressAt = now
 	}
 }
 
+// Snapshot of resolution counters for the UI
+func (r *Resolution) currentStats() *Stats {
+	stats := r.stats
+	stats.Iterations = r.iterationCounter
+	stats.Current = r.state().Name
+	stats.Resolved = len(r.state().Activated.ActivatedByName())
+	if f, ok := r.SpecProvider.(fetchCounter); ok {
+		stats.Fetches = f.FetchCount()
+	}
+
+	if r.endedAt.IsZero() {
+		stats.Elapsed = time.Since(r.startedAt)
+	} else {
+		stats.Elapsed = r.endedAt.Sub(r.startedAt)
+	}
+	return &stats
+}
+
 func (r *Resolution) attemptToActivate() error {
 	r.debug("Attempting to activate %s", r.possibility())
 	state := r.state()
@@ -316,22 +430,25 @@ func (r *Resolution) attemptToActivateExistingSpec(node *Vertex) error {
 
 func (r *Resolution) attemptToSwapPossibility() (bool, error) {
 	s, p := r.state(), r.possibility()
-	swapped := s.Activated.Dup()
-	swapped.vertexNamed(s.Name).Payload = p
+	snapshot := s.Activated.Snapshot()
+	s.Activated.setPayload(s.Name, p)
 
-	for _, req := range swapped.requirementsFor(s.Name) {
-		if !r.isRequirementSatisfiedBy(req, swapped, p) {
-			return false, nil
+	satisfied := true
+	for _, req := range s.Activated.requirementsFor(s.Name) {
+		if !r.isRequirementSatisfiedBy(req, s.Activated, p) {
+			satisfied = false
+			break
 		}
 	}
 
-	if !r.isNewSpecSatisfied() {
+	// Undo the swap until the new spec is known to be satisfied
+	s.Activated.Restore(snapshot)
+	if !satisfied || !r.isNewSpecSatisfied() {
 		return false, nil
 	}
 
-	actualVertex := s.Activated.vertexNamed(s.Name)
-	actualVertex.Payload = p
-	r.fixSwappedChildren(actualVertex)
+	s.Activated.setPayload(s.Name, p)
+	r.fixSwappedChildren(s.Activated.vertexNamed(s.Name))
 	return true, r.activateSpec()
 }
 
@@ -385,7 +502,9 @@ func (r *Resolution) isNewSpecSatisfied() bool {
 }
 
 func (r *Resolution) lockedRequirementNamed(name string) types.Requirement {
-	if spec := r.Base.PayloadFor(name); spec != nil {
+	if _, forced := r.Overrides[name]; forced {
+		return nil // Overrides also replace locked versions
+	} else if spec := r.Base.PayloadFor(name); spec != nil {
 		return &lockedRequirement{spec}
 	}
 	return nil
@@ -403,9 +522,11 @@ func (r *Resolution) activateSpec() error {
 	state, possibility := r.state(), r.possibility()
 	delete(state.Conflicts, state.Name)
 	r.debug("Activated %s at %s", state.Name, possibility)
-	vertex := state.Activated.vertexNamed(state.Name)
-	vertex.Payload = possibility
+	state.Activated.setPayload(state.Name, possibility)
 	r.debug("ACTIVATED %s %s", possibility.Name, possibility.Version)
+	if r.Events != nil {
+		r.Events.OnActivate(possibility)
+	}
 	return r.requireNestedDependenciesFor(possibility)
 }
 
@@ -450,11 +571,12 @@ func (r *Resolution) pushStateForRequirements(reqs []types.Requirement, requires
 	}
 
 	if len(reqs) > 0 {
-		r, reqs := reqs[0], reqs[1:]
-		newState.Name = r.Name()
-		newState.Requirement = r
+		req, reqs := reqs[0], reqs[1:]
+		newState.Name = req.Name()
+		newState.Requirement = req
 		newState.Requirements = reqs
-		newState.Possibilities = provider.SearchFor(r)
+		newState.Possibilities = r.searchFor(req)
+		newState.Candidates = newState.Possibilities
 	} else {
 		newState.Requirements = []types.Requirement{}
 		newState.Possibilities = []types.Specification{}
@@ -477,7 +599,37 @@ func (r *Resolution) handleMissingOrPushDependencyState(state *State) {
 // ==== Proxy methods to SpecificationProvider ====State
 func (r *Resolution) isRequirementSatisfiedBy(req types.Requirement, graph *Graph, p types.Specification) bool {
 	//  return req != nil && r.SpecProvider.IsRequirementSatisfiedBy(req, graph, p)
-	return r.SpecProvider.IsRequirementSatisfiedBy(req, graph, p)
+	return r.SpecProvider.IsRequirementSatisfiedBy(r.Overrides.apply(req), graph, p)
+}
+
+// Possibilities are ordered so that the preferred one is last
+func (r *Resolution) searchFor(req types.Requirement) []types.Specification {
+	req = r.Overrides.apply(req)
+	specs := r.SpecProvider.SearchFor(req)
+	r.stats.Searches++
+	if r.Events != nil {
+		r.Events.OnSearch(req, len(specs))
+	}
+	if r.Strategy == MinimalStrategy {
+		return reversedSpecs(specs)
+	}
+	return specs
+}
+
+// Possibilities that were considered for the state, sorted by version
+func (r *Resolution) candidates(state *State) []types.Specification {
+	if r.Strategy == MinimalStrategy {
+		return reversedSpecs(state.Candidates)
+	}
+	return append([]types.Specification{}, state.Candidates...)
+}
+
+func reversedSpecs(specs []types.Specification) []types.Specification {
+	reversed := make([]types.Specification, len(specs))
+	for i, s := range specs {
+		reversed[len(specs)-1-i] = s
+	}
+	return reversed
 }
 
 func (r *Resolution) allowMissing(req types.Requirement) bool {
@@ -6,6 +6,7 @@ import (
 	"github.com/mdy/melody/resolver/flex"
 	"github.com/mdy/melody/resolver/types"
 	"github.com/mdy/melody/version"
+	"strings"
 )
 
 func NewSpec(n, v string) *Specification {
@@ -54,6 +55,11 @@ func (s *Dependency) String() string {
 	return fmt.Sprintf("GemDependency(%s %s)", s.NameStr, s.RangeStr)
 }
 
+// Versions allowed by the range with RubyGems version semantics
+func (s *Dependency) VersionSet() (version.Set, error) {
+	return version.ParseSet(strings.Trim(s.RangeStr, " "), VersionParser)
+}
+
 // FIXME: Describe how this is different from SemVer
 func (s *Dependency) SatisfiedBy(spec types.Specification) (bool, error) {
 	if s.NameStr != spec.Name() {
@@ -20,6 +20,7 @@ type State struct {
 	Requirement   types.Requirement
 	Requirements  types.Requirements
 	Possibilities []types.Specification
+	Candidates    []types.Specification // Every possibility found for Requirement
 	Conflicts     Conflicts
 }
 
@@ -54,6 +55,7 @@ func (s *State) popPossibilityState() *State {
 		Activated:     s.Activated.Dup(),
 		Conflicts:     s.Conflicts.Dup(),
 		Possibilities: possibilites,
+		Candidates:    s.Candidates,
 	}
 }
 
@@ -0,0 +1,34 @@
+package resolver
+
+import (
+	"fmt"
+)
+
+// Strategy to choose between versions that satisfy all requirements
+type Strategy int
+
+const (
+	NewestStrategy  Strategy = iota // Prefer newest versions (default)
+	MinimalStrategy Strategy = iota // Minimal version selection
+)
+
+var strategyNames = map[string]Strategy{
+	"":        NewestStrategy,
+	"newest":  NewestStrategy,
+	"minimal": MinimalStrategy,
+}
+
+// Strategy by name as specified in Melody.toml or CLI flags
+func ParseStrategy(name string) (Strategy, error) {
+	if s, ok := strategyNames[name]; ok {
+		return s, nil
+	}
+	return NewestStrategy, fmt.Errorf("Unknown resolution strategy: %s", name)
+}
+
+func (s Strategy) String() string {
+	if s == MinimalStrategy {
+		return "minimal"
+	}
+	return "newest"
+}
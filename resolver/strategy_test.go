@@ -0,0 +1,47 @@
+package resolver
+
+import (
+	"github.com/mdy/melody/resolver/types"
+	c "gopkg.in/check.v1"
+)
+
+func (s *MySuite) Test_ParseStrategy(t *c.C) {
+	for name, expected := range map[string]Strategy{
+		"":        NewestStrategy,
+		"newest":  NewestStrategy,
+		"minimal": MinimalStrategy,
+	} {
+		strategy, err := ParseStrategy(name)
+		t.Assert(err, c.IsNil)
+		t.Assert(strategy, c.Equals, expected)
+	}
+
+	_, err := ParseStrategy("oldest")
+	t.Assert(err, c.NotNil)
+}
+
+func (s *MySuite) Test_Resolver_MinimalStrategy(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", ">= 1.1"),
+		gemSpec("app", "1.1.0", "lib", ">= 1.2"),
+		gemSpec("other", "1.0.0", "lib", ">= 1.2"),
+		gemSpec("lib", "1.0.0"),
+		gemSpec("lib", "1.1.0"),
+		gemSpec("lib", "1.2.0"),
+		gemSpec("lib", "2.0.0"),
+	)
+
+	requested := types.Requirements{gemDependency("app", ">= 1.0"), gemDependency("other", ">= 1.0")}
+
+	// Default strategy prefers newest versions
+	resolver := NewResolver(provider, &silentUI{})
+	out, err := resolver.Resolve(requested, nil)
+	t.Assert(err, c.IsNil)
+	t.Assert(out.String(), c.Equals, "Graph(Spec(app 1.1.0) Spec(lib 2.0.0) Spec(other 1.0.0))")
+
+	// Minimal picks lowest versions that satisfy all requirements
+	resolver.Strategy = MinimalStrategy
+	out, err = resolver.Resolve(requested, nil)
+	t.Assert(err, c.IsNil)
+	t.Assert(out.String(), c.Equals, "Graph(Spec(app 1.0.0) Spec(lib 1.2.0) Spec(other 1.0.0))")
+}
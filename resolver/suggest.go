@@ -0,0 +1,209 @@
+package resolver
+
+import (
+	"fmt"
+	"github.com/mdy/melody/resolver/types"
+	"strings"
+	"time"
+)
+
+// Limit constrained resolutions that are attempted for each requirement chain
+const maxSuggestionAttempts = 5
+
+// Populate conflicts with concrete remedies.  Every suggestion is verified by
+// re-running a resolution with the proposed change, so this may be expensive
+func (r *Resolver) Suggest(requested types.Requirements, base *Graph, err *VersionConflictError) {
+	if base == nil {
+		base = NewGraph()
+	}
+
+	for name, conflict := range Conflicts(*err) {
+		conflict.Suggestions = r.suggestionsFor(name, conflict, requested, base)
+	}
+}
+
+func (r *Resolver) suggestionsFor(name string, c *Conflict, requested types.Requirements, base *Graph) []string {
+	reqs := c.conflictingRequirements()
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	// Some version satisfies everything, except for the lockfile
+	if spec := r.satisfyingAll(reqs); spec != nil {
+		locked, ok := c.LockedRequirement.(*lockedRequirement)
+		if ok && r.isResolvable(requested, base, nil, name) {
+			return []string{fmt.Sprintf("remove the %s pin for %s (%s) to allow %s",
+				r.provider.NameForLockingDependencySource(), name, locked.Version(), spec.Version())}
+		}
+		return nil
+	}
+
+	// Try to update dependents that impose a requirement
+	suggestions := []string{}
+	for _, branch := range c.RequirementTrees {
+		if len(branch) < 2 {
+			continue
+		}
+		parentReq, req := branch[len(branch)-2], branch[len(branch)-1]
+		current := c.ActivatedByName[parentReq.Name()]
+		if s := r.suggestParentUpdate(name, parentReq, current, reqs.without(req), requested, base); s != "" {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	if len(suggestions) == 0 {
+		constraints := make([]string, len(reqs))
+		for i, req := range reqs {
+			constraints[i] = constraintFor(req)
+		}
+
+		both := ""
+		if len(constraints) == 2 {
+			both = "both "
+		} else if len(constraints) > 2 {
+			both = "all of "
+		}
+
+		msg := "no published version of %s satisfies %s%s"
+		suggestions = append(suggestions, fmt.Sprintf(msg, name, both, strings.Join(constraints, " and ")))
+	}
+
+	return suggestions
+}
+
+// Look for another version of a dependent that allows a compatible spec
+func (r *Resolver) suggestParentUpdate(name string, parentReq types.Requirement, current types.Specification, others conflictRequirements, requested types.Requirements, base *Graph) string {
+	candidates := r.searchFor(parentReq)
+
+	// Possibilities are sorted by version, so try the closest upgrades
+	// first and only then fall back to the closest downgrades.  Without a
+	// known current version, the newest candidates are tried as changes
+	upgrades, downgrades, changes := []types.Specification{}, []types.Specification{}, reversedSpecs(candidates)
+	for i, s := range candidates {
+		if current != nil && SpecEqual(s, current) {
+			upgrades, changes = candidates[i+1:], nil
+			for j := i - 1; j >= 0; j-- {
+				downgrades = append(downgrades, candidates[j])
+			}
+		}
+	}
+
+	attempts := append(append(append([]types.Specification{}, upgrades...), downgrades...), changes...)
+	for i, parent := range attempts {
+		if i >= maxSuggestionAttempts {
+			break
+		}
+
+		reqs := append(conflictRequirements{}, others...)
+		for _, dep := range r.provider.DependenciesFor(parent) {
+			if dep.Name() == name {
+				reqs = append(reqs, dep)
+			}
+		}
+
+		spec := r.satisfyingAll(reqs)
+		if len(reqs) > 0 && spec == nil {
+			continue
+		}
+
+		pin := &lockedRequirement{parent}
+		if !r.isResolvable(requested, base, pin, name, parent.Name()) {
+			continue
+		}
+
+		verb := "updating"
+		if i >= len(upgrades)+len(downgrades) {
+			verb = "changing"
+		} else if i >= len(upgrades) {
+			verb = "downgrading"
+		}
+
+		if spec == nil {
+			return fmt.Sprintf("%s %s to %s would drop its dependency on %s", verb, parent.Name(), parent.Version(), name)
+		}
+		return fmt.Sprintf("%s %s to %s would allow %s %s", verb, parent.Name(), parent.Version(), name, spec.Version())
+	}
+
+	return ""
+}
+
+// Newest specification that satisfies every one of the requirements
+func (r *Resolver) satisfyingAll(reqs []types.Requirement) types.Specification {
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	specs := r.searchFor(reqs[0])
+	for i := len(specs) - 1; i >= 0; i-- {
+		ok := true
+		for _, req := range reqs[1:] {
+			ok = ok && r.isRequirementSatisfiedBy(req, specs[i])
+		}
+		if ok {
+			return specs[i]
+		}
+	}
+	return nil
+}
+
+// Possibilities sorted by version, where forced requirements win
+func (r *Resolver) searchFor(req types.Requirement) []types.Specification {
+	return r.provider.SearchFor(r.Overrides.apply(req))
+}
+
+func (r *Resolver) isRequirementSatisfiedBy(req types.Requirement, spec types.Specification) bool {
+	return r.provider.IsRequirementSatisfiedBy(r.Overrides.apply(req), nil, spec)
+}
+
+// Re-run a constrained resolution with an optional pin and some names unlocked
+func (r *Resolver) isResolvable(requested types.Requirements, base *Graph, pin types.Requirement, unlock ...string) bool {
+	newBase := base.Dup()
+	for _, name := range unlock {
+		newBase.DetachNamedVertex(name)
+	}
+
+	if pin != nil {
+		requested = append(requested.Dup(), pin)
+	}
+
+	silent := &Resolver{provider: r.provider, ui: &silentUI{}, Strategy: r.Strategy, Overrides: r.Overrides}
+	_, err := silent.Resolve(requested, newBase)
+	return err == nil
+}
+
+// Requirements that are in conflict (the tail of every requirement tree)
+type conflictRequirements []types.Requirement
+
+func (c *Conflict) conflictingRequirements() conflictRequirements {
+	reqs, seen := conflictRequirements{}, map[types.Requirement]bool{}
+	for _, branch := range c.RequirementTrees {
+		if len(branch) == 0 {
+			continue
+		}
+		if req := branch[len(branch)-1]; !seen[req] {
+			reqs = append(reqs, req)
+			seen[req] = true
+		}
+	}
+	return reqs
+}
+
+// Copy of requirements without the specified one
+func (c conflictRequirements) without(req types.Requirement) conflictRequirements {
+	out := conflictRequirements{}
+	for _, r := range c {
+		if r != req {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// UI that ignores everything for internal resolutions
+type silentUI struct{}
+
+func (ui *silentUI) ProgressRate() time.Duration          { return time.Hour }
+func (ui *silentUI) IndicateProgress(_ *Stats)            {}
+func (ui *silentUI) BeforeResolution()                    {}
+func (ui *silentUI) AfterResolution(_ *Stats)             {}
+func (ui *silentUI) Debug(depth int, args ...interface{}) {}
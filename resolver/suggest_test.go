@@ -0,0 +1,134 @@
+package resolver
+
+import (
+	"github.com/mdy/melody/resolver/rubygem"
+	"github.com/mdy/melody/resolver/types"
+	c "gopkg.in/check.v1"
+)
+
+func gemSpec(name, version string, deps ...string) *rubygem.Specification {
+	spec := rubygem.NewSpec(name, version)
+	for i := 0; i+1 < len(deps); i += 2 {
+		spec.Dependencies = append(spec.Dependencies, gemDependency(deps[i], deps[i+1]))
+	}
+	return spec
+}
+
+func gemIndex(specs ...*rubygem.Specification) *testSpecProvider {
+	provider := &testSpecProvider{Index: map[string][]*rubygem.Specification{}}
+	for _, s := range specs {
+		provider.Index[s.Name()] = append(provider.Index[s.Name()], s)
+	}
+	return provider
+}
+
+func (s *MySuite) Test_Resolver_Suggest(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", "< 2.0"),
+		gemSpec("app", "1.4.0", "lib", ">= 2.0"),
+		gemSpec("other", "1.0.0", "lib", ">= 2.0"),
+		gemSpec("lib", "1.0.0"),
+		gemSpec("lib", "2.0.0"),
+	)
+
+	requested := types.Requirements{gemDependency("app", "~> 1.0"), gemDependency("other", "~> 1.0")}
+	resolver := NewResolver(provider, &silentUI{})
+
+	// Locked older "app" can't work with "other"
+	base := NewGraph()
+	base.addVertex("app", gemSpec("app", "1.0.0"), true)
+	_, err := resolver.Resolve(requested, base)
+	t.Assert(err, c.FitsTypeOf, &VersionConflictError{})
+
+	vErr := err.(*VersionConflictError)
+	resolver.Suggest(requested, base, vErr)
+	t.Assert(suggestionsFor(vErr), c.DeepEquals, []string{
+		"updating app to 1.4.0 would allow lib 2.0.0",
+	})
+
+	// Nothing can be done if no version is published
+	requested = types.Requirements{gemDependency("lib", "< 1.0"), gemDependency("other", "~> 1.0")}
+	_, err = resolver.Resolve(requested, nil)
+	t.Assert(err, c.FitsTypeOf, &VersionConflictError{})
+
+	vErr = err.(*VersionConflictError)
+	resolver.Suggest(requested, nil, vErr)
+	t.Assert(suggestionsFor(vErr), c.DeepEquals, []string{
+		"no published version of lib satisfies < 1.0",
+	})
+}
+
+func (s *MySuite) Test_Resolver_SuggestLockfile(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", ">= 1.0"),
+		gemSpec("lib", "1.0.0"),
+		gemSpec("lib", "2.0.0"),
+	)
+
+	requested := types.Requirements{gemDependency("app", "~> 1.0"), gemDependency("lib", ">= 2.0")}
+	resolver := NewResolver(provider, &silentUI{})
+
+	base := NewGraph()
+	base.addVertex("app", gemSpec("app", "1.0.0"), true)
+	base.addVertex("lib", gemSpec("lib", "1.0.0"), true)
+	_, err := resolver.Resolve(requested, base)
+	t.Assert(err, c.FitsTypeOf, &VersionConflictError{})
+
+	vErr := err.(*VersionConflictError)
+	resolver.Suggest(requested, base, vErr)
+	t.Assert(suggestionsFor(vErr), c.DeepEquals, []string{
+		"remove the Lockfile pin for lib (1.0.0) to allow 2.0.0",
+	})
+}
+
+func (s *MySuite) Test_Resolver_SuggestUnknownCurrent(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", "< 2.0"),
+		gemSpec("app", "1.4.0", "lib", ">= 2.0"),
+		gemSpec("app", "1.5.0", "lib", ">= 2.0"),
+		gemSpec("other", "1.0.0", "lib", ">= 2.0"),
+		gemSpec("lib", "1.0.0"),
+		gemSpec("lib", "2.0.0"),
+	)
+
+	appReq, otherReq := gemDependency("app", "~> 1.0"), gemDependency("other", "~> 1.0")
+	requested := types.Requirements{appReq, otherReq}
+	resolver := NewResolver(provider, &silentUI{})
+
+	// Without a current version, the newest candidates are tried first
+	others := conflictRequirements{gemDependency("lib", ">= 2.0")}
+	t.Assert(resolver.suggestParentUpdate("lib", appReq, nil, others, requested, NewGraph()), c.Equals,
+		"changing app to 1.5.0 would allow lib 2.0.0")
+	t.Assert(resolver.suggestParentUpdate("lib", appReq, gemSpec("app", "1.0.0"), others, requested, NewGraph()), c.Equals,
+		"updating app to 1.4.0 would allow lib 2.0.0")
+}
+
+func (s *MySuite) Test_Resolver_SuggestOverrides(t *c.C) {
+	provider := gemIndex(
+		gemSpec("app", "1.0.0", "lib", "< 2.0"),
+		gemSpec("app", "1.4.0", "lib", ">= 2.0"),
+		gemSpec("other", "1.0.0", "lib", ">= 2.0"),
+		gemSpec("lib", "1.0.0"),
+		gemSpec("lib", "2.0.0"),
+		gemSpec("lib", "3.0.0"),
+	)
+
+	appReq, otherReq := gemDependency("app", "~> 1.0"), gemDependency("other", "~> 1.0")
+	requested := types.Requirements{appReq, otherReq}
+	resolver := NewResolver(provider, &silentUI{})
+	resolver.Overrides = NewOverrides(types.Requirements{gemDependency("lib", "= 2.0.0")})
+
+	// Suggested versions follow the forced lib version
+	others := conflictRequirements{gemDependency("lib", ">= 2.0")}
+	t.Assert(resolver.suggestParentUpdate("lib", appReq, gemSpec("app", "1.0.0"), others, requested, NewGraph()), c.Equals,
+		"updating app to 1.4.0 would allow lib 2.0.0")
+}
+
+// Flat list of suggestions across all conflicts
+func suggestionsFor(err *VersionConflictError) []string {
+	out := []string{}
+	for _, report := range err.Report() {
+		out = append(out, report.Suggestions...)
+	}
+	return out
+}
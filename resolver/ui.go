@@ -11,14 +11,60 @@ import (
 
 type UI interface {
 	ProgressRate() time.Duration
-	IndicateProgress()
+	IndicateProgress(*Stats)
 	BeforeResolution()
-	AfterResolution()
+	AfterResolution(*Stats)
 	Debug(int, ...interface{})
 }
 
+// Resolution counters that are reported to the UI
+type Stats struct {
+	Iterations int
+	Resolved   int
+	Backtracks int
+	Searches   int
+	Fetches    int
+	Current    string
+	Elapsed    time.Duration
+}
+
+// Providers that can report the number of remote fetches
+type fetchCounter interface {
+	FetchCount() int
+}
+
+// Amount of resolver output
+type Verbosity int
+
+const (
+	QuietVerbosity   Verbosity = iota // Nothing but errors
+	NormalVerbosity  Verbosity = iota // Progress reporting
+	VerboseVerbosity Verbosity = iota // Progress and debug output
+)
+
 func NewStdoutUI() UI {
-	return &writerUI{os.Stdout, false}
+	return NewWriterUI(os.Stdout)
+}
+
+func NewWriterUI(w io.Writer) UI {
+	return &writerUI{w, false}
+}
+
+// UI that shows live counters on a terminal, or plain
+// progress lines when the output is piped to a file
+func NewTerminalUI(f *os.File, v Verbosity) UI {
+	base := &writerUI{f, v == VerboseVerbosity}
+	if v == QuietVerbosity {
+		return &quietUI{base}
+	} else if v == VerboseVerbosity || !isTerminal(f) {
+		return &lineUI{base}
+	}
+	return &liveUI{base}
+}
+
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
 }
 
 type writerUI struct {
@@ -30,7 +76,7 @@ func (ui *writerUI) ProgressRate() time.Duration {
 	return 333 * time.Millisecond
 }
 
-func (ui *writerUI) IndicateProgress() {
+func (ui *writerUI) IndicateProgress(_ *Stats) {
 	fmt.Fprintf(ui, ".")
 }
 
@@ -38,7 +84,7 @@ func (ui *writerUI) BeforeResolution() {
 	fmt.Fprintf(ui, "♫ Resolving dependencies...")
 }
 
-func (ui *writerUI) AfterResolution() {
+func (ui *writerUI) AfterResolution(_ *Stats) {
 	fmt.Fprintln(ui, " done.")
 }
 
@@ -59,3 +105,66 @@ func (ui *writerUI) Debug(depth int, args ...interface{}) {
 		fmt.Fprintln(ui, prefix+line)
 	}
 }
+
+// Counters in a human-readable form
+func (s *Stats) String() string {
+	return fmt.Sprintf("%d resolved, %d backtracks, %d fetches",
+		s.Resolved, s.Backtracks, s.Fetches)
+}
+
+// Live counters that are redrawn on a single terminal line
+type liveUI struct {
+	*writerUI
+}
+
+func (ui *liveUI) ProgressRate() time.Duration {
+	return 100 * time.Millisecond
+}
+
+func (ui *liveUI) BeforeResolution() {
+	fmt.Fprint(ui, "♫ Resolving dependencies...")
+}
+
+func (ui *liveUI) IndicateProgress(stats *Stats) {
+	fmt.Fprintf(ui, "\r\033[K♫ Resolving dependencies... %s", stats)
+	if stats.Current != "" {
+		fmt.Fprintf(ui, " (%s)", stats.Current)
+	}
+}
+
+func (ui *liveUI) AfterResolution(stats *Stats) {
+	fmt.Fprintf(ui, "\r\033[K♫ Resolved dependencies: %s in %s\n",
+		stats, stats.Elapsed.Round(time.Millisecond))
+}
+
+// Plain progress lines that work well in logs
+type lineUI struct {
+	*writerUI
+}
+
+func (ui *lineUI) ProgressRate() time.Duration {
+	return 5 * time.Second
+}
+
+func (ui *lineUI) BeforeResolution() {
+	fmt.Fprintln(ui, "♫ Resolving dependencies...")
+}
+
+func (ui *lineUI) IndicateProgress(stats *Stats) {
+	fmt.Fprintf(ui, "♫ Resolving %s: %s\n", stats.Current, stats)
+}
+
+func (ui *lineUI) AfterResolution(stats *Stats) {
+	fmt.Fprintf(ui, "♫ Resolved dependencies: %s in %s\n",
+		stats, stats.Elapsed.Round(time.Millisecond))
+}
+
+// No output at all
+type quietUI struct {
+	*writerUI
+}
+
+func (ui *quietUI) ProgressRate() time.Duration { return time.Hour }
+func (ui *quietUI) IndicateProgress(_ *Stats)   {}
+func (ui *quietUI) BeforeResolution()           {}
+func (ui *quietUI) AfterResolution(_ *Stats)    {}
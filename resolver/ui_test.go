@@ -0,0 +1,44 @@
+package resolver
+
+import (
+	c "gopkg.in/check.v1"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+func (s *MySuite) Test_TerminalUI(t *c.C) {
+	file, err := ioutil.TempFile("", "melody-ui")
+	t.Assert(err, c.IsNil)
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	stats := &Stats{Resolved: 3, Backtracks: 1, Fetches: 2, Current: "lib", Elapsed: time.Second}
+	t.Assert(stats.String(), c.Equals, "3 resolved, 1 backtracks, 2 fetches")
+
+	// Quiet output writes nothing at all
+	ui := NewTerminalUI(file, QuietVerbosity)
+	ui.BeforeResolution()
+	ui.IndicateProgress(stats)
+	ui.AfterResolution(stats)
+	ui.Debug(0, "debug")
+
+	// Files are not terminals, so output is plain lines
+	ui = NewTerminalUI(file, NormalVerbosity)
+	t.Assert(ui, c.FitsTypeOf, &lineUI{})
+	ui.BeforeResolution()
+	ui.IndicateProgress(stats)
+	ui.Debug(0, "hidden")
+	ui.AfterResolution(stats)
+
+	// Verbose output includes debug messages
+	ui = NewTerminalUI(file, VerboseVerbosity)
+	ui.Debug(1, "visible %d", 1)
+
+	raw, err := ioutil.ReadFile(file.Name())
+	t.Assert(err, c.IsNil)
+	t.Assert(string(raw), c.Equals, "♫ Resolving dependencies...\n"+
+		"♫ Resolving lib: 3 resolved, 1 backtracks, 2 fetches\n"+
+		"♫ Resolved dependencies: 3 resolved, 1 backtracks, 2 fetches in 1s\n"+
+		" visible 1\n")
+}
@@ -0,0 +1,72 @@
+package resolver
+
+import (
+	"fmt"
+	"github.com/mdy/melody/resolver/types"
+	"sort"
+)
+
+// Validate a resolved graph before it's locked.  This makes sure that every
+// requested dependency is satisfied by a root vertex, every vertex has been
+// activated, every edge requirement holds, and there are no orphans left
+func (g *Graph) Validate(requested types.Requirements, sp SpecificationProvider) error {
+	return g.ValidateWithOverrides(requested, sp, nil)
+}
+
+// Validate a graph where forced requirements replace any other on their package
+func (g *Graph) ValidateWithOverrides(requested types.Requirements, sp SpecificationProvider, o Overrides) error {
+	problems := []string{}
+
+	// Explicitly requested dependencies
+	for _, req := range requested {
+		vertex := g.rootVertexNamed(req.Name())
+		if vertex == nil || vertex.Payload == nil {
+			if !sp.AllowMissing(req) {
+				problems = append(problems, fmt.Sprintf("%s is not resolved", req))
+			}
+		} else if !sp.IsRequirementSatisfiedBy(o.apply(req), g, vertex.Payload) {
+			msg := fmt.Sprintf("%s is not satisfied by %s", req, vertex.Payload)
+			problems = append(problems, msg)
+		}
+	}
+
+	// Every vertex should be activated and reachable
+	reachable := g.reachableFromRoots()
+	nodes := verticesByName(g.Nodes())
+	sort.Sort(nodes)
+	for _, node := range nodes {
+		vertex := node.(*Vertex)
+		if vertex.Payload == nil {
+			problems = append(problems, fmt.Sprintf("%s is not activated", vertex.Name))
+			continue
+		}
+
+		if !reachable[vertex.Name] {
+			problems = append(problems, fmt.Sprintf("%s is orphaned", vertex.Payload))
+		}
+
+		for _, parent := range g.To(vertex) {
+			req := g.Edge(parent, vertex).(*Edge).Requirement
+			if req != nil && !sp.IsRequirementSatisfiedBy(o.apply(req), g, vertex.Payload) {
+				msg := fmt.Sprintf("%s (required by %s) is not satisfied by %s",
+					req, parent.(*Vertex).Name, vertex.Payload)
+				problems = append(problems, msg)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &InvalidGraphError{Problems: problems}
+	}
+	return nil
+}
+
+// Names of vertices reachable from a root.  Vertices on a detached cycle all
+// have parents, so only reachability tells them apart from real dependencies
+func (g *Graph) reachableFromRoots() map[string]bool {
+	reachable := map[string]bool{}
+	for _, name := range g.RootNames() {
+		g.collectSuccessors(name, reachable)
+	}
+	return reachable
+}
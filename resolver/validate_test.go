@@ -0,0 +1,70 @@
+package resolver
+
+import (
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/resolver/rubygem"
+	"github.com/mdy/melody/resolver/types"
+	c "gopkg.in/check.v1"
+)
+
+func gemDependency(name, version string) types.Requirement {
+	return &rubygem.Dependency{Dependency: *flex.NewDependency(name, version)}
+}
+
+func (s *MySuite) Test_Graph_Validate(t *c.C) {
+	provider := &testSpecProvider{}
+	requested := types.Requirements{gemDependency("app", "~> 1.0")}
+
+	// Complete graph passes validation
+	graph := NewGraph()
+	graph.addVertex("app", rubygem.NewSpec("app", "1.1.0"), true)
+	graph.addChildVertex("lib", rubygem.NewSpec("lib", "2.0.0"), []string{"app"}, gemDependency("lib", ">= 2.0"))
+	t.Assert(graph.Validate(requested, provider), c.IsNil)
+
+	// Requested and edge requirements are checked
+	graph = NewGraph()
+	graph.addVertex("app", rubygem.NewSpec("app", "2.0.0"), true)
+	graph.addChildVertex("lib", rubygem.NewSpec("lib", "1.0.0"), []string{"app"}, gemDependency("lib", ">= 2.0"))
+	err := graph.Validate(requested, provider)
+	t.Assert(err, c.FitsTypeOf, &InvalidGraphError{})
+	t.Assert(err.(*InvalidGraphError).Problems, c.HasLen, 2)
+
+	// Missing payloads and orphans are reported
+	graph = NewGraph()
+	graph.addVertex("app", rubygem.NewSpec("app", "1.0.0"), true)
+	graph.addChildVertex("lib", nil, []string{"app"}, nil)
+	graph.addVertex("orphan", rubygem.NewSpec("orphan", "1.0.0"), false)
+	err = graph.Validate(requested, provider)
+	t.Assert(err, c.FitsTypeOf, &InvalidGraphError{})
+	t.Assert(err.(*InvalidGraphError).Problems, c.DeepEquals, []string{
+		"lib is not activated",
+		"Spec(orphan 1.0.0) is orphaned",
+	})
+
+	// Cycles detached from every root are orphans too
+	graph = NewGraph()
+	graph.addVertex("app", rubygem.NewSpec("app", "1.0.0"), true)
+	graph.addVertex("loose", rubygem.NewSpec("loose", "1.0.0"), false)
+	graph.addChildVertex("tied", rubygem.NewSpec("tied", "1.0.0"), []string{"loose"}, nil)
+	graph.setEdge("tied", "loose", nil)
+	err = graph.Validate(requested, provider)
+	t.Assert(err, c.FitsTypeOf, &InvalidGraphError{})
+	t.Assert(err.(*InvalidGraphError).Problems, c.DeepEquals, []string{
+		"Spec(loose 1.0.0) is orphaned",
+		"Spec(tied 1.0.0) is orphaned",
+	})
+
+	// Unresolved requested dependency
+	graph = NewGraph()
+	err = graph.Validate(requested, provider)
+	t.Assert(err, c.FitsTypeOf, &InvalidGraphError{})
+	t.Assert(err.(*InvalidGraphError).Problems, c.HasLen, 1)
+}
+
+func (s *MySuite) Test_Graph_UnresolvedNames(t *c.C) {
+	graph := NewGraph()
+	graph.addVertex("root", rubygem.NewSpec("root", "1.0.0"), true)
+	graph.addChildVertex("b", nil, []string{"root"}, nil)
+	graph.addChildVertex("a", nil, []string{"root"}, nil)
+	t.Assert(graph.unresolvedNames(), c.DeepEquals, []string{"a", "b"})
+}
@@ -2,6 +2,7 @@ package version
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -54,47 +55,289 @@ func SatisfiesRange(v ParsingVersion, rStr string) (bool, error) {
 // ParseRange parses a range and returns a Range.
 // If the range could not be parsed an error is returned.
 func ParseRange(str string, verPar Parser) (Range, error) {
-	var tok Token
-	var lit string
-	var outputFn, andFn Range
-	currentOp := EQ // Default if omitted
+	e, err := parseExpr(str, verPar)
+	if err != nil {
+		return nil, err
+	}
+
+	outputFn := Range(func(Version) bool { return false })
+	for i, terms := range e {
+		var andFn Range
+		for _, t := range terms {
+			andFn = andFn.AND(comparators[t.op].toRangeFunc(t.version))
+		}
+		if i == 0 {
+			outputFn = andFn
+		} else {
+			outputFn = outputFn.OR(andFn)
+		}
+	}
+
+	return outputFn, nil
+}
+
+// Comparison against a single version (>=1.2.0)
+type term struct {
+	op      Token
+	version Version
+}
+
+// Terms joined by AND, which are then joined by OR
+type expr [][]term
+
+// Logical AND of two expressions (nil is a missing expression)
+func (e expr) and(f expr) expr {
+	if e == nil {
+		return f
+	}
+
+	out := expr{}
+	for _, a := range e {
+		for _, b := range f {
+			out = append(out, append(append([]term{}, a...), b...))
+		}
+	}
+	return out
+}
+
+func singleTerm(op Token, v Version) expr {
+	return expr{{{op, v}}}
+}
+
+// Parse a range into comparisons joined by AND/OR
+func parseExpr(str string, verPar Parser) (expr, error) {
+	var output, section expr
 
 	// Rewrite shortcuts into valid ranges
 	if rewrite, ok := shortcuts[str]; ok {
 		str = rewrite
 	}
 
-	// Use text/scanner to parse versions and operators
-	for s := newRangeScanner(strings.NewReader(str)); tok != EOF; {
-		tok, _, lit = s.Scan()
+	tokens, err := scanRange(str)
+	if err != nil {
+		return nil, err
+	}
 
-		if tok == INVALID {
-			return nil, fmt.Errorf("Illegal token: %s", lit)
-		} else if tok == WS {
+	// Join sections by AND, and each group of sections by OR
+	for i := 0; i < len(tokens); i++ {
+		t, next := tokens[i], tokens[i+1]
+		if t.tok == OR {
+			if section == nil || next.tok == EOF || next.tok == OR {
+				return nil, fmt.Errorf("Missing version next to %q at position %d", t.text(), t.pos)
+			}
+			output, section = append(output, section...), nil
 			continue
-		} else if tok == OR {
-			outputFn, andFn = outputFn.OR(andFn), nil
-		} else if tok != VERSION {
-			currentOp = tok
-		} else { // tok == VERSION
-			version, err := verPar.Parse(lit)
-			if err != nil {
+		} else if t.tok == EOF {
+			if section == nil {
+				return nil, fmt.Errorf("Missing version at position %d", t.pos)
+			}
+			break
+		}
+
+		// Hyphen range between two versions (1.2 - 1.4)
+		var e expr
+		if t.tok == VERSION && next.tok == HYPHEN {
+			if upper := tokens[i+2]; upper.tok != VERSION {
+				return nil, fmt.Errorf("Missing version after \"-\" at position %d", next.pos)
+			} else if e, err = hyphenRange(t, upper, verPar); err != nil {
+				return nil, err
+			}
+			i += 2
+		} else if t.tok == VERSION {
+			if e, err = versionRange(EQ, t, verPar); err != nil {
 				return nil, err
 			}
+		} else if _, ok := comparators[t.tok]; !ok {
+			return nil, fmt.Errorf("Unexpected %q at position %d", t.text(), t.pos)
+		} else if next.tok != VERSION {
+			return nil, fmt.Errorf("Missing version after %q at position %d", t.text(), t.pos)
+		} else if e, err = versionRange(t.tok, next, verPar); err != nil {
+			return nil, err
+		} else {
+			i++
+		}
+
+		section = section.and(e)
+	}
+
+	return append(output, section...), nil
+}
+
+type rangeToken struct {
+	tok Token
+	pos Pos
+	lit string
+}
+
+// Token as typed in the range for error messages
+func (t rangeToken) text() string {
+	if t.tok == OR {
+		return "||"
+	}
+	return t.tok.String()
+}
+
+// Scan all tokens without whitespace, always ending in a few EOF tokens.
+// Sections next to each other are joined by AND anyway, so commas are
+// skipped too and empty AND terms (">=1.0," or ">=1.0,,<2") are allowed
+func scanRange(str string) ([]rangeToken, error) {
+	tokens := []rangeToken{}
+	for s := newRangeScanner(strings.NewReader(str)); ; {
+		tok, pos, lit := s.Scan()
+		if tok == INVALID {
+			return nil, fmt.Errorf("Illegal token %q at position %d", lit, pos)
+		} else if tok == EOF {
+			eof := rangeToken{EOF, pos, ""}
+			return append(tokens, eof, eof, eof), nil
+		} else if tok != WS && tok != AND {
+			tokens = append(tokens, rangeToken{tok, pos, lit})
+		}
+	}
+}
+
+// Range for a single operator and version, which may contain wildcards
+func versionRange(op Token, t rangeToken, verPar Parser) (expr, error) {
+	parts, wildcard, err := splitWildcard(t)
+	if err != nil {
+		return nil, err
+	} else if !wildcard {
+		version, err := verPar.Parse(t.lit)
+		if err != nil {
+			return nil, err
+		}
+		return singleTerm(op, version), nil
+	}
+
+	lower, upper, err := xRangeBounds(parts, verPar)
+	if err != nil {
+		return nil, err
+	}
+
+	// Operators apply to the whole X-Range, like with npm
+	if upper == nil { // Any version (*)
+		if op == GT || op == LT || op == NEQ {
+			return expr{}, nil
+		}
+		return singleTerm(GTE, lower), nil
+	}
+
+	switch op {
+	case GT:
+		return singleTerm(GTE, upper), nil
+	case GTE:
+		return singleTerm(GTE, lower), nil
+	case LT:
+		return singleTerm(LT, lower), nil
+	case LTE:
+		return singleTerm(LT, upper), nil
+	case NEQ:
+		return expr{{{LT, lower}}, {{GTE, upper}}}, nil
+	}
+	return expr{{{GTE, lower}, {LT, upper}}}, nil
+}
+
+// Range between two versions, including any version matching a partial upper
+func hyphenRange(t1, t2 rangeToken, verPar Parser) (expr, error) {
+	lower, err := versionRange(GTE, t1, verPar)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, wildcard, err := splitWildcard(t2)
+	if err != nil {
+		return nil, err
+	} else if wildcard || isPartial(parts) {
+		_, upper, err := xRangeBounds(parts, verPar)
+		if err != nil || upper == nil {
+			return lower, err
+		}
+		return lower.and(singleTerm(LT, upper)), nil
+	}
+
+	upper, err := verPar.Parse(t2.lit)
+	if err != nil {
+		return nil, err
+	}
+	return lower.and(singleTerm(LTE, upper)), nil
+}
+
+// Numeric parts before the first wildcard, or all parts without wildcards.
+// Only major, minor and patch can be wildcards, not prerelease parts (1.2.3-beta.x)
+func splitWildcard(t rangeToken) ([]string, bool, error) {
+	parts, core := strings.Split(t.lit, "."), t.lit
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+
+	for i, part := range strings.Split(core, ".") {
+		if part != "x" && part != "X" && part != "*" {
+			continue
+		}
+
+		// Everything after a wildcard must be a wildcard too (1.x.x)
+		for _, rest := range parts[i:] {
+			if rest != "x" && rest != "X" && rest != "*" {
+				return nil, false, fmt.Errorf("Invalid wildcard version %q at position %d", t.lit, t.pos)
+			}
+		}
 
-			// Convert operator into comparator
-			comp, ok := comparators[currentOp]
-			if !ok {
-				return nil, fmt.Errorf("Invalid comparator: %s", currentOp)
+		for _, prefix := range parts[:i] {
+			if _, err := strconv.ParseUint(prefix, 10, 64); err != nil {
+				return nil, false, fmt.Errorf("Invalid wildcard version %q at position %d", t.lit, t.pos)
 			}
+		}
+		return parts[:i], true, nil
+	}
 
-			// Convert comparator into a Range
-			andFn = andFn.AND(comp.toRangeFunc(version))
-			currentOp = EQ // Reset for next section
+	if !isDigit(rune(t.lit[0])) {
+		return nil, false, fmt.Errorf("Invalid version %q at position %d", t.lit, t.pos)
+	}
+	return parts, false, nil
+}
+
+// Numeric version with less than three parts (1.2)
+func isPartial(parts []string) bool {
+	for _, part := range parts {
+		if _, err := strconv.ParseUint(part, 10, 64); err != nil {
+			return false
 		}
 	}
+	return len(parts) < 3
+}
 
-	return outputFn.OR(andFn), nil
+// Lowest version of an X-Range and the version just after it (1.2 => 1.3.0)
+func xRangeBounds(parts []string, verPar Parser) (Version, Version, error) {
+	if len(parts) == 0 {
+		lower, err := verPar.Parse("0.0.0")
+		return lower, nil, err
+	}
+
+	nums := make([]uint64, len(parts))
+	for i, part := range parts {
+		nums[i], _ = strconv.ParseUint(part, 10, 64)
+	}
+
+	lower, err := verPar.Parse(joinVersion(nums))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nums[len(nums)-1]++
+	upper, err := verPar.Parse(joinVersion(nums))
+	return lower, upper, err
+}
+
+// Semantic version string padded with zeros
+func joinVersion(nums []uint64) string {
+	parts := []string{"0", "0", "0"}
+	for i, num := range nums {
+		if i < len(parts) {
+			parts[i] = strconv.FormatUint(num, 10)
+		} else {
+			parts = append(parts, strconv.FormatUint(num, 10))
+		}
+	}
+	return strings.Join(parts, ".")
 }
 
 // Create a Range for a comparator/version pair
@@ -97,6 +97,67 @@ func TestParseRange(t *testing.T) {
 			{"1.4.3-beta", false},
 		}},
 
+		// X-Range Expressions
+		{"1.2.x", []tv{
+			{"1.1.9", false},
+			{"1.2.0", true},
+			{"1.2.9", true},
+			{"1.3.0", false},
+		}},
+		{"1.*", []tv{
+			{"0.9.0", false},
+			{"1.0.0", true},
+			{"1.9.9", true},
+			{"2.0.0", false},
+		}},
+		{"1.X.x", []tv{
+			{"1.4.0", true},
+			{"2.0.0", false},
+		}},
+		{">1.2.x", []tv{
+			{"1.2.9", false},
+			{"1.3.0", true},
+		}},
+		{"<=1.x", []tv{
+			{"1.9.9", true},
+			{"2.0.0", false},
+		}},
+		{">=1.0.0 || x", []tv{
+			{"0.1.0", true},
+			{"0.1.0-beta", false},
+		}},
+
+		// Hyphen Expressions
+		{"1.2 - 1.4", []tv{
+			{"1.1.9", false},
+			{"1.2.0", true},
+			{"1.4.9", true},
+			{"1.5.0", false},
+		}},
+		{"1.2.3 - 1.4.5", []tv{
+			{"1.2.2", false},
+			{"1.2.3", true},
+			{"1.4.5", true},
+			{"1.4.6", false},
+		}},
+		{"1.x - 2.x || 4.0.0", []tv{
+			{"0.9.0", false},
+			{"2.9.0", true},
+			{"3.0.0", false},
+			{"4.0.0", true},
+		}},
+
+		// Version prefix
+		{"^v1.2.0", []tv{
+			{"1.1.0", false},
+			{"1.4.0", true},
+			{"2.0.0", false},
+		}},
+		{"v1.2.0 - V1.3", []tv{
+			{"1.3.5", true},
+			{"1.4.0", false},
+		}},
+
 		// Simple Expression errors
 		{">>1.2.3", nil},
 		{"!1.2.3", nil},
@@ -127,6 +188,37 @@ func TestParseRange(t *testing.T) {
 			{"1.2.4", false},
 			{"1.2.5", false},
 		}},
+		{">=1.0, <2.0", []tv{
+			{"0.9.0", false},
+			{"1.0.0", true},
+			{"1.9.9", true},
+			{"2.0.0", false},
+		}},
+		{">=1.0,<2.0 || >=3.0 , <3.1", []tv{
+			{"1.0.0", true},
+			{"2.0.0", false},
+			{"3.0.5", true},
+			{"3.1.0", false},
+		}},
+		// Empty AND terms are skipped, like commas used to be
+		{">=1.0,", []tv{
+			{"0.9.0", false},
+			{"1.0.0", true},
+		}},
+		{",>=1.0,,<2.0", []tv{
+			{"1.0.0", true},
+			{"2.0.0", false},
+		}},
+		{">=2.0 || , <1.0", []tv{
+			{"0.9.0", true},
+			{"1.0.0", false},
+			{"2.0.0", true},
+		}},
+		// Prerelease parts are never wildcards
+		{">=1.2.3-beta.x", []tv{
+			{"1.2.2", false},
+			{"1.2.3", true},
+		}},
 		// OR Expressions
 		{">1.2.2 || <1.2.4", []tv{
 			{"1.2.2", true},
@@ -172,3 +264,27 @@ func TestParseRange(t *testing.T) {
 
 	}
 }
+
+func TestParseRangeErrors(t *testing.T) {
+	tests := []struct {
+		i, err string
+	}{
+		{">>1.2.3", `Missing version after ">" at position 0`},
+		{"string", `Illegal token "s" at position 0`},
+		{">=1.0 &", `Illegal token "&" at position 6`},
+		{">=1.0 ||", `Missing version next to "||" at position 6`},
+		{"1.x-beta", `Invalid wildcard version "1.x-beta" at position 0`},
+		{"1.0 - ", `Missing version after "-" at position 4`},
+		{">=1.0 - 2.0", `Unexpected "-" at position 6`},
+		{"1.x.3", `Invalid wildcard version "1.x.3" at position 0`},
+		{"1.2 xyz", `Invalid version "xyz" at position 4`},
+		{">=", `Missing version after ">=" at position 0`},
+	}
+
+	for _, tc := range tests {
+		_, err := version.ParseRange(tc.i, flex.VersionParser)
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("Invalid error for case %q: Expected %q, got: %v", tc.i, tc.err, err)
+		}
+	}
+}
@@ -23,7 +23,7 @@ func (s *scanner) Scan() (tok Token, pos Pos, lit string) {
 	// Recognize and consume complex segments
 	if isWhitespace(ch0) {
 		return s.scanWhitespace()
-	} else if isDigit(ch0) {
+	} else if isDigit(ch0) || isWildcard(ch0) {
 		return s.scanVersion()
 	} else if ch0 == eof {
 		return EOF, pos, ""
@@ -31,6 +31,11 @@ func (s *scanner) Scan() (tok Token, pos Pos, lit string) {
 
 	// Try to match two char operators
 	ch1, _ := s.r.read()
+	if (ch0 == 'v' || ch0 == 'V') && isDigit(ch1) {
+		_, _, lit = s.scanVersion() // Drop the "v" prefix
+		return VERSION, pos, lit
+	}
+
 	switch string([]rune{ch0, ch1}) {
 	case "~>":
 		return TILDE, pos, ""
@@ -63,6 +68,10 @@ func (s *scanner) Scan() (tok Token, pos Pos, lit string) {
 		return GT, pos, ""
 	case '<':
 		return LT, pos, ""
+	case ',':
+		return AND, pos, ""
+	case '-':
+		return HYPHEN, pos, ""
 	}
 
 	return INVALID, pos, string(ch0)
@@ -95,7 +104,7 @@ func (s *scanner) scanWhile(isMatch func(rune) bool) (pos Pos, lit string) {
 
 // isWhitespace returns true if the rune is a space, tab, or newline.
 func isWhitespace(ch rune) bool {
-	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == ','
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
 }
 
 // isLetter returns true if the rune is a letter.
@@ -108,9 +117,14 @@ func isDigit(ch rune) bool {
 	return (ch >= '0' && ch <= '9')
 }
 
-// isVersion includes digits, letters, hyphens, etc
+// isWildcard returns true for "x", "X" and "*" version components.
+func isWildcard(ch rune) bool {
+	return ch == 'x' || ch == 'X' || ch == '*'
+}
+
+// isVersion includes digits, letters, hyphens, wildcards, etc
 func isVersion(ch rune) bool {
-	return isDigit(ch) || isLetter(ch) || ch == '.' || ch == '-' || ch == '+'
+	return isDigit(ch) || isLetter(ch) || ch == '.' || ch == '-' || ch == '+' || ch == '*'
 }
 
 // reader represents a buffered rune reader used by the scanner.
@@ -179,6 +193,8 @@ const (
 	VERSION // 1.2.3-bla
 	TILDE   // ~
 	CARET   // ^
+	AND     // ,
+	HYPHEN  // -
 
 	OR  // OR
 	EQ  // ==
@@ -197,6 +213,8 @@ var tokens = [...]string{
 	VERSION: "VERSION",
 	TILDE:   "~",
 	CARET:   "^",
+	AND:     ",",
+	HYPHEN:  "-",
 
 	OR:  "OR",
 	EQ:  "=",
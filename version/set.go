@@ -0,0 +1,252 @@
+package version
+
+import (
+	"sort"
+	"strings"
+)
+
+// Bound of an interval, a nil Version is unbounded
+type Bound struct {
+	Version   Version
+	Inclusive bool
+}
+
+// Versions between a lower and an upper bound
+type Interval struct {
+	Lower Bound
+	Upper Bound
+}
+
+// Set of versions as sorted, disjoint intervals.  Unlike a Range it can be
+// printed, combined with other sets and checked for emptiness
+type Set []Interval
+
+// ParseSet parses a range into a Set of versions.
+// If the range could not be parsed an error is returned.
+func ParseSet(str string, verPar Parser) (Set, error) {
+	e, err := parseExpr(str, verPar)
+	if err != nil {
+		return nil, err
+	}
+
+	output := Set{}
+	for _, terms := range e {
+		section := Set{Interval{}}
+		for _, t := range terms {
+			section = section.Intersect(t.set())
+		}
+		output = output.Union(section)
+	}
+	return output, nil
+}
+
+// Versions matched by a single comparison
+func (t term) set() Set {
+	v := t.version
+	switch t.op {
+	case NEQ:
+		return Set{{Upper: Bound{v, false}}, {Lower: Bound{v, false}}}
+	case GT:
+		return Set{{Lower: Bound{v, false}}}
+	case GTE:
+		return Set{{Lower: Bound{v, true}}}
+	case LT:
+		return Set{{Upper: Bound{v, false}}}
+	case LTE:
+		return Set{{Upper: Bound{v, true}}}
+	case CARET:
+		return Set{{Bound{v, true}, Bound{v.MajorBump(), false}}}
+	case TILDE:
+		return Set{{Bound{v, true}, Bound{v.MinorBump(), false}}}
+	}
+	return Set{{Bound{v, true}, Bound{v, true}}}
+}
+
+// Versions in both sets
+func (s Set) Intersect(o Set) Set {
+	output := Set{}
+	for _, a := range s {
+		for _, b := range o {
+			i := Interval{a.Lower, a.Upper}
+			if compareLower(b.Lower, i.Lower) > 0 {
+				i.Lower = b.Lower
+			}
+			if compareUpper(b.Upper, i.Upper) < 0 {
+				i.Upper = b.Upper
+			}
+			output = append(output, i)
+		}
+	}
+	return output.normalize()
+}
+
+// Versions in either set
+func (s Set) Union(o Set) Set {
+	return append(append(Set{}, s...), o...).normalize()
+}
+
+// Versions not in the set
+func (s Set) Complement() Set {
+	output, lower := Set{}, Bound{}
+	for _, i := range s.normalize() {
+		if i.Lower.Version != nil {
+			output = append(output, Interval{lower, Bound{i.Lower.Version, !i.Lower.Inclusive}})
+		}
+		if i.Upper.Version == nil {
+			return output.normalize()
+		}
+		lower = Bound{i.Upper.Version, !i.Upper.Inclusive}
+	}
+	return append(output, Interval{Lower: lower}).normalize()
+}
+
+// No version can be in the set
+func (s Set) IsEmpty() bool {
+	return len(s.normalize()) == 0
+}
+
+// Check if the version is in the set.  Like a Range, prereleases are only
+// included by intervals bounded by a prerelease of the same version
+func (s Set) Contains(v Version) bool {
+	for _, i := range s {
+		if i.Lower.Version != nil && compareLower(Bound{v, true}, i.Lower) < 0 {
+			continue
+		} else if i.Upper.Version != nil && compareUpper(Bound{v, true}, i.Upper) > 0 {
+			continue
+		} else if !v.IsPrerelease() || isPrereleaseOf(i.Lower, v) || isPrereleaseOf(i.Upper, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Canonical representation, which parses back into the same non-empty set
+func (s Set) String() string {
+	parts := []string{}
+	for _, i := range s.normalize() {
+		parts = append(parts, i.String())
+	}
+
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, " || ")
+}
+
+func (i Interval) String() string {
+	lower, upper := i.Lower, i.Upper
+	if lower.Version == nil && upper.Version == nil {
+		return "*"
+	} else if lower.Version != nil && upper.Version != nil && lower.Version.Compare(upper.Version) == 0 {
+		return lower.Version.String()
+	}
+
+	parts := []string{}
+	if lower.Version != nil && lower.Inclusive {
+		parts = append(parts, ">="+lower.Version.String())
+	} else if lower.Version != nil {
+		parts = append(parts, ">"+lower.Version.String())
+	}
+
+	if upper.Version != nil && upper.Inclusive {
+		parts = append(parts, "<="+upper.Version.String())
+	} else if upper.Version != nil {
+		parts = append(parts, "<"+upper.Version.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func (i Interval) isEmpty() bool {
+	if i.Lower.Version == nil || i.Upper.Version == nil {
+		return false
+	}
+	diff := sign(i.Lower.Version.Compare(i.Upper.Version))
+	return diff > 0 || (diff == 0 && !(i.Lower.Inclusive && i.Upper.Inclusive))
+}
+
+// Sorted intervals without empty or overlapping ones
+func (s Set) normalize() Set {
+	sorted := Set{}
+	for _, i := range s {
+		if !i.isEmpty() {
+			sorted = append(sorted, i)
+		}
+	}
+	sort.Sort(intervalsByLower(sorted))
+
+	output := Set{}
+	for _, i := range sorted {
+		last := len(output) - 1
+		if last >= 0 && overlaps(output[last].Upper, i.Lower) {
+			if compareUpper(i.Upper, output[last].Upper) > 0 {
+				output[last].Upper = i.Upper
+			}
+		} else {
+			output = append(output, i)
+		}
+	}
+	return output
+}
+
+// Check if an upper bound reaches a lower bound with no gap in between
+func overlaps(upper, lower Bound) bool {
+	if upper.Version == nil || lower.Version == nil {
+		return true
+	}
+	diff := sign(lower.Version.Compare(upper.Version))
+	return diff < 0 || (diff == 0 && (upper.Inclusive || lower.Inclusive))
+}
+
+// Order of lower bounds, where nil comes first
+func compareLower(a, b Bound) int {
+	if a.Version == nil || b.Version == nil {
+		return boolToInt(b.Version == nil) - boolToInt(a.Version == nil)
+	} else if diff := sign(a.Version.Compare(b.Version)); diff != 0 {
+		return diff
+	}
+	return boolToInt(b.Inclusive) - boolToInt(a.Inclusive)
+}
+
+// Order of upper bounds, where nil comes last
+func compareUpper(a, b Bound) int {
+	if a.Version == nil || b.Version == nil {
+		return boolToInt(a.Version == nil) - boolToInt(b.Version == nil)
+	} else if diff := sign(a.Version.Compare(b.Version)); diff != 0 {
+		return diff
+	}
+	return boolToInt(a.Inclusive) - boolToInt(b.Inclusive)
+}
+
+// Prerelease bound of the same main version (see toRangeFunc)
+func isPrereleaseOf(b Bound, v Version) bool {
+	if b.Version == nil || !b.Version.IsPrerelease() {
+		return false
+	}
+	diff := b.Version.Compare(v)
+	return diff > -2 && diff < 2
+}
+
+func sign(i int) int {
+	if i < 0 {
+		return -1
+	} else if i > 0 {
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Sorting of intervals by their lower bound
+type intervalsByLower Set
+
+func (s intervalsByLower) Len() int      { return len(s) }
+func (s intervalsByLower) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s intervalsByLower) Less(i, j int) bool {
+	return compareLower(s[i].Lower, s[j].Lower) < 0
+}
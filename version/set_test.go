@@ -0,0 +1,106 @@
+package version_test
+
+import (
+	"github.com/mdy/melody/resolver/flex"
+	"github.com/mdy/melody/version"
+	"testing"
+)
+
+func mustParseSet(t *testing.T, str string) version.Set {
+	s, err := version.ParseSet(str, flex.VersionParser)
+	if err != nil {
+		t.Fatalf("Error parsing set %q: %s", str, err)
+	}
+	return s
+}
+
+func TestParseSet(t *testing.T) {
+	tests := []struct {
+		i, s string
+	}{
+		{"*", ">=0.0.0"},
+		{">=1.0.0 <2.0.0", ">=1.0.0 <2.0.0"},
+		{"<2.0.0, >=1.0.0", ">=1.0.0 <2.0.0"},
+		{"^1.2.3", ">=1.2.3 <2"},
+		{"~1.2.3", ">=1.2.3 <1.3"},
+		{"1.2.x", ">=1.2.0 <1.3.0"},
+		{"1.2 - 1.4", ">=1.2 <1.5.0"},
+		{"!=1.2.3", "<1.2.3 || >1.2.3"},
+		{"=1.2.3", "1.2.3"},
+		{">=1.4 <1.2", "none"},
+		{"<*", "none"},
+		{">=2.0.0 || <1.0.0 || >=1.5.0 <2.1.0", "<1.0.0 || >=1.5.0"},
+		{"<=1.0.0 || >=1.0.0", "*"},
+		{"<1.0.0 || >1.0.0", "<1.0.0 || >1.0.0"},
+	}
+
+	for _, tc := range tests {
+		if s := mustParseSet(t, tc.i).String(); s != tc.s {
+			t.Errorf("Invalid set for %q: Expected %q, got: %q", tc.i, tc.s, s)
+		}
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	tests := []struct {
+		a, b, intersect, union string
+		empty                  bool
+	}{
+		{">=1.4", "<1.2", "none", "<1.2 || >=1.4", true},
+		{">=1.0 <2.0", "^1.5.0", ">=1.5.0 <2.0", ">=1.0 <2.0", false},
+		{"1.2.3", "~1.2.0", "1.2.3", ">=1.2.0 <1.3", false},
+		{">1.0.0", "<=1.0.0", "none", "*", true},
+		{"<1.0.0 || >=2.0.0", "1.5.0 || 2.5.0", "2.5.0", "<1.0.0 || 1.5.0 || >=2.0.0", false},
+	}
+
+	for _, tc := range tests {
+		a, b := mustParseSet(t, tc.a), mustParseSet(t, tc.b)
+		if s := a.Intersect(b).String(); s != tc.intersect {
+			t.Errorf("Invalid intersection of %q and %q: Expected %q, got: %q", tc.a, tc.b, tc.intersect, s)
+		}
+		if s := a.Union(b).String(); s != tc.union {
+			t.Errorf("Invalid union of %q and %q: Expected %q, got: %q", tc.a, tc.b, tc.union, s)
+		}
+		if e := a.Intersect(b).IsEmpty(); e != tc.empty {
+			t.Errorf("Invalid emptiness of %q and %q: Expected %t, got: %t", tc.a, tc.b, tc.empty, e)
+		}
+	}
+}
+
+func TestSetComplement(t *testing.T) {
+	tests := []struct {
+		i, s string
+	}{
+		{">=1.0.0 <2.0.0", "<1.0.0 || >=2.0.0"},
+		{"1.2.3", "<1.2.3 || >1.2.3"},
+		{"<1.0.0 || >=1.5.0 <2.0.0", ">=1.0.0 <1.5.0 || >=2.0.0"},
+		{">=1.4 <1.2", "*"},
+		{"<=1.0.0 || >=1.0.0", "none"},
+	}
+
+	for _, tc := range tests {
+		if s := mustParseSet(t, tc.i).Complement().String(); s != tc.s {
+			t.Errorf("Invalid complement of %q: Expected %q, got: %q", tc.i, tc.s, s)
+		}
+	}
+}
+
+func TestSetContains(t *testing.T) {
+	tests := []string{
+		">1.2.3", ">=1.2.3", "<1.2.3", "<=1.2.3", "1.2.3", "!=1.2.3", "^1.2.3", "~1.2.3",
+		">=1.4.2-beta.2", ">1.2.2 <1.2.5 !=1.2.4", "<1.2.2 || >1.2.4", "1.2.x", "1.2 - 1.4",
+	}
+	versions := []string{"1.2.2", "1.2.3", "1.2.4", "1.2.5-beta", "1.3.0", "1.4.0-beta", "1.4.2-beta.3", "1.4.3", "2.0.0"}
+
+	// Sets should match the same versions as ranges
+	for _, str := range tests {
+		set := mustParseSet(t, str)
+		r, _ := version.ParseRange(str, flex.VersionParser)
+		for _, vStr := range versions {
+			v, _ := flex.VersionParser.Parse(vStr)
+			if expected, actual := r(v), set.Contains(v); expected != actual {
+				t.Errorf("Invalid for case %q containing %q: Expected %t, got: %t", str, vStr, expected, actual)
+			}
+		}
+	}
+}